@@ -3,10 +3,16 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 
+	T "my-ls-1/cmd/terminal/lsOptions"
+	A "my-ls-1/internal/audit"
 	L "my-ls-1/internal/list"
+	S "my-ls-1/internal/sort"
+	H "my-ls-1/pkg/checksum"
 	FI "my-ls-1/pkg/fileinfo"
 	OP "my-ls-1/pkg/options"
+	U "my-ls-1/pkg/utils"
 	C "my-ls-1/pkg/utils/color"
 )
 
@@ -15,25 +21,48 @@ func main() {
 	//Initialize color function
 	C.InitColorMap()
 
+	//Pick the name collation from the locale
+	S.InitCollation()
+
 	//Parse command line flags and arguments
 	options, args := OP.ParseFlags()
 
+	//Every column named with --columns has to be a registered one
+	for _, name := range options.Columns {
+		if _, ok := U.LookupColumn(name); !ok {
+			fmt.Printf("ls: invalid argument '%s' for '--columns'\n", name)
+			os.Exit(1)
+		}
+	}
+
+	//Checksums of an earlier run are reused while the files are unchanged
+	if options.HashCache != "" {
+		if err := H.LoadCache(options.HashCache); err != nil {
+			fmt.Printf("ls: cannot read checksum cache '%s': %v\n", options.HashCache, err)
+		}
+	}
+
 	if len(args) == 0 {
 		args = []string{"."}
 	}
-	args, _ = AddFullPathAndSort(args)
+	multiple := len(args) > 1
+	args = SortOperands(args, options)
 	for i, arg := range args {
-		if len(args) > 1 {
+		if multiple {
 			if i > 0 {
 				fmt.Println()
 			}
-			filIf, _ := os.Stat(arg)
-			if FI.CreateFileInfo(arg, filIf); filIf.IsDir() {
-				fmt.Printf("%s:\n", arg)
+			if info, err := statOperand(arg); err == nil && info.IsDir() {
+				fmt.Printf("%s:\n", U.QuoteName(arg, options))
 			}
 		}
 
-		fileInfo, err := os.Stat(arg)
+		A.SetRoot(arg)
+		if options.Hardlinks != "" {
+			FI.IndexHardlinks(hardlinkTree(arg), options.Recursive)
+		}
+
+		fileInfo, err := statOperand(arg)
 		if err != nil {
 			fmt.Printf("ls: cannot access '%s': %v\n", arg, err)
 			continue
@@ -49,27 +78,81 @@ func main() {
 			L.ListSingleFile(arg, options)
 		}
 	}
-}
+	if err := H.SaveCache(); err != nil {
+		fmt.Printf("ls: cannot write checksum cache '%s': %v\n", options.HashCache, err)
+	}
 
-/*This function will take an array of entries, and will sort the array
-by seperating the directories from the files and prepend the files and
-returns a sorted array*/
-func AddFullPathAndSort(shortPaths []string) ([]string, error) {
+	//--audit ends with the warnings section and fails when there are any
+	if options.Audit && A.PrintWarnings(options) > 0 {
+		os.Exit(1)
+	}
+}
 
-	// Separate files and directories
-	var files, dirs []string
-	for _, path := range shortPaths {
-		info, err := os.Stat(path)
+/*This function orders the command line operands with the same sort keys,
+-r and directory grouping as the entries of a listing. Operands that do not
+exist are reported right away and dropped, and so are file operands the filter
+flags reject; directories are always listed. Files still come before the
+directories, which are listed with a header, unless --group-directories-first
+puts the directories first*/
+func SortOperands(args []string, options OP.Options) []string {
+	var operands []FI.FileInfo
+	for _, arg := range args {
+		info, err := statOperand(arg)
 		if err != nil {
-			return nil, err
+			fmt.Printf("ls: cannot access '%s': %v\n", arg, err)
+			continue
 		}
-		if info.IsDir() {
-			dirs = append(dirs, path)
-		} else {
-			files = append(files, path)
+		if !info.IsDir() && !matchesFilter(arg, options) {
+			continue
 		}
+		operand := FI.CreateFileInfo(T.Dir(arg), info)
+		operand.Name = arg
+		operands = append(operands, operand)
+	}
+
+	S.SortFiles(operands, options)
+	if options.GroupDirs != "first" || S.Unsorted(options) {
+		filesFirst := options
+		filesFirst.GroupDirs = "last"
+		S.GroupDirectories(operands, filesFirst)
+	}
+
+	sorted := make([]string, len(operands))
+	for i, operand := range operands {
+		sorted[i] = operand.Name
 	}
+	return sorted
+}
 
-	// Combine files and directories
-	return append(files, dirs...), nil
+/*Follows an operand that is a symlink, so links to directories are listed as
+directories. A symlink whose target can not be reached is the link itself*/
+func statOperand(arg string) (os.FileInfo, error) {
+	info, err := os.Stat(arg)
+	if err != nil {
+		if linkInfo, lerr := os.Lstat(arg); lerr == nil {
+			return linkInfo, nil
+		}
+	}
+	return info, err
+}
+
+/*Reports whether a file operand passes the filter flags. It is tested the way
+ListSingleFile shows it, so a symlink operand is the link itself*/
+func matchesFilter(arg string, options OP.Options) bool {
+	if options.Filter == nil {
+		return true
+	}
+	info, err := os.Lstat(arg)
+	if err != nil {
+		return true
+	}
+	return options.Filter.Match(FI.CreateFileInfo(T.Dir(arg), info))
+}
+
+//The tree --hardlinks looks for other names in: the argument, or the directory of a file argument
+func hardlinkTree(arg string) string {
+	if info, err := os.Stat(arg); err == nil && !info.IsDir() {
+		return filepath.Dir(arg)
+	}
+	return arg
 }
@@ -4,9 +4,12 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"syscall"
 
+	D "my-ls-1/internal/du"
 	S "my-ls-1/internal/sort"
 	FI "my-ls-1/pkg/fileinfo"
+	F "my-ls-1/pkg/filter"
 	OP "my-ls-1/pkg/options"
 )
 
@@ -26,6 +29,16 @@ func ReadDirectory(path string, options OP.Options) ([]FI.FileInfo, error) {
 
 	files := make([]FI.FileInfo, 0, len(entries))
 
+	// entries living on another device than the directory are mount points;
+	// nothing is marked when the directory itself can not be stat'ed
+	var parentDev uint64
+	parentKnown := false
+	if info, err := dir.Stat(); err == nil {
+		if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+			parentDev, parentKnown = stat.Dev, true
+		}
+	}
+
 	if options.ShowHidden {
 		parentPath := fmt.Sprintf("%s/..", path)
 		AddSpecialEntry(parentPath, "..", &files)
@@ -41,9 +54,16 @@ func ReadDirectory(path string, options OP.Options) ([]FI.FileInfo, error) {
 			continue
 		}
 		fileInfo := FI.CreateFileInfo(path, info)
+		fileInfo.IsMountPoint = parentKnown && fileInfo.IsDir && fileInfo.Dev != parentDev
 		files = append(files, fileInfo)
 	}
 
+	files = F.Apply(files, options.Filter)
+
+	if options.DiskUsage != "" {
+		D.AddDirectoryTotals(files, options)
+	}
+
 	S.SortFiles(files, options)
 
 	return files, nil
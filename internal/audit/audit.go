@@ -0,0 +1,143 @@
+package audit
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	FI "my-ls-1/pkg/fileinfo"
+	OP "my-ls-1/pkg/options"
+	U "my-ls-1/pkg/utils"
+	W "my-ls-1/pkg/utils/width"
+)
+
+// A Finding is one risky entry found by --audit.
+type Finding struct {
+	Mode   string // the mode string of the entry, as the long format shows it
+	Path   string
+	Reason string
+}
+
+var (
+	findings []Finding
+	seen     = map[string]bool{}
+	root     string
+)
+
+/*Sets the command line argument the following entries are listed from.
+Symlinks leaving it are reported. For a file argument the tree is the
+directory holding it*/
+func SetRoot(path string) {
+	if info, err := os.Stat(path); err == nil && !info.IsDir() {
+		path = filepath.Dir(path)
+	}
+	root = absolute(path)
+}
+
+/*This function checks the entries of a listing for --audit and records what
+it finds. An entry listed more than once is only reported once per reason*/
+func Check(files []FI.FileInfo) {
+	for _, file := range files {
+		if file.Name == "." || file.Name == ".." {
+			continue
+		}
+		for _, reason := range risks(file) {
+			key := file.Path + "\x00" + reason
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			findings = append(findings, Finding{
+				Mode:   U.FormatFileMode(file.Mode),
+				Path:   file.Path,
+				Reason: reason,
+			})
+		}
+	}
+}
+
+//Returns why an entry is risky, nothing when it is not
+func risks(file FI.FileInfo) []string {
+	var reasons []string
+	mode := U.FormatFileMode(file.Mode)
+
+	// the mode string is "drwxrwxrwt": others' write bit at 8, execute or sticky at 9
+	if !file.IsLink && mode[8] == 'w' {
+		if !file.IsDir {
+			reasons = append(reasons, "world-writable file")
+		} else if mode[9] != 't' && mode[9] != 'T' {
+			reasons = append(reasons, "world-writable directory without the sticky bit")
+		}
+	}
+	if mode[0] == '-' && (mode[3] == 's' || mode[3] == 'S') {
+		reasons = append(reasons, "setuid file owned by "+FI.UserName(file.Uid))
+	}
+	if mode[0] == '-' && (mode[6] == 's' || mode[6] == 'S') {
+		reasons = append(reasons, "setgid file of group "+FI.GroupName(file.Gid))
+	}
+	if !FI.UserExists(file.Uid) {
+		reasons = append(reasons, fmt.Sprintf("owned by nonexistent uid %d", file.Uid))
+	}
+	if !FI.GroupExists(file.Gid) {
+		reasons = append(reasons, fmt.Sprintf("owned by nonexistent gid %d", file.Gid))
+	}
+	if file.IsLink && !within(linkDestination(file), root) {
+		reasons = append(reasons, "symlink pointing outside the listed tree to "+file.LinkTarget)
+	}
+	if (mode[0] == 'b' || mode[0] == 'c') && !within(absolute(file.Path), "/dev") {
+		reasons = append(reasons, "device node outside /dev")
+	}
+	return reasons
+}
+
+//Returns where a symlink points, relative targets resolved from its directory
+func linkDestination(file FI.FileInfo) string {
+	target := file.LinkTarget
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(file.Path), target)
+	}
+	return absolute(target)
+}
+
+func absolute(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
+
+//Reports whether path is dir or lies below it. The comparison is lexical
+func within(path, dir string) bool {
+	if dir == "" {
+		return true
+	}
+	return path == dir || dir == "/" || strings.HasPrefix(path, dir+"/")
+}
+
+/*This function prints the warnings section after the listings and returns the
+number of findings. Nothing is printed when the audit found nothing*/
+func PrintWarnings(options OP.Options) int {
+	if len(findings) == 0 {
+		return 0
+	}
+
+	modeWidth := 0
+	for _, finding := range findings {
+		modeWidth = max(modeWidth, len(finding.Mode))
+	}
+
+	// -R already ends every listing with an empty line
+	if !options.Recursive {
+		fmt.Println()
+	}
+	fmt.Printf("audit: %d warning", len(findings))
+	if len(findings) != 1 {
+		fmt.Print("s")
+	}
+	fmt.Println()
+	for _, finding := range findings {
+		fmt.Printf("%s %s: %s\n", W.PadRight(finding.Mode, modeWidth), U.QuoteName(finding.Path, options), finding.Reason)
+	}
+	return len(findings)
+}
@@ -0,0 +1,173 @@
+package du
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"syscall"
+
+	FI "my-ls-1/pkg/fileinfo"
+	OP "my-ls-1/pkg/options"
+)
+
+// Usage is the recursive size of a directory tree.
+type Usage struct {
+	Bytes  int64   // apparent size, the sum of the file sizes
+	Blocks int64   // allocated size in 1 KB blocks, like FileInfo.Blocks
+	Errors []error // directories and entries that could not be read
+}
+
+/*This function replaces the size and blocks of every directory in files by the
+totals of the tree below it, for --du and --du-blocks. With --du-blocks the
+size column shows the allocated bytes instead of the apparent ones. The
+directories are measured concurrently, all walks of the listing share one
+limit on the directories read at a time. A directory that could not be read
+completely is reported, its total only covers what was read. ".." is left
+alone*/
+func AddDirectoryTotals(files []FI.FileInfo, options OP.Options) {
+	sem := make(chan struct{}, runtime.NumCPU()*4)
+	errs := make([][]error, len(files))
+
+	var wg sync.WaitGroup
+	for i := range files {
+		if !files[i].IsDir || files[i].Name == ".." {
+			continue
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(file *FI.FileInfo, errs *[]error) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			usage := measure(file.Path, options.OneFS, sem)
+			file.Blocks = usage.Blocks
+			file.Size = usage.Bytes
+			if options.DiskUsage == "blocks" {
+				file.Size = usage.Blocks * 1024
+			}
+			*errs = usage.Errors
+		}(&files[i], &errs[i])
+	}
+	wg.Wait()
+
+	for i, dirErrs := range errs {
+		for _, err := range dirErrs {
+			if pathErr, ok := err.(*fs.PathError); ok {
+				err = fmt.Errorf("cannot read '%s': %v", pathErr.Path, pathErr.Err)
+			}
+			fmt.Printf("ls: %v, the total of '%s' is incomplete\n", err, files[i].Name)
+		}
+	}
+}
+
+/*Returns the usage of the tree rooted at path, the root included. Files with
+several hard links are counted once, and with oneFileSystem directories on
+other devices are skipped*/
+func Measure(path string, oneFileSystem bool) Usage {
+	return measure(path, oneFileSystem, make(chan struct{}, runtime.NumCPU()*4))
+}
+
+/*Walks a tree with sem bounding the directories read at once. The caller may
+hold a slot of sem already, the walk never waits for one*/
+func measure(path string, oneFileSystem bool, sem chan struct{}) Usage {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return Usage{Errors: []error{err}}
+	}
+	root, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return Usage{}
+	}
+
+	w := &walker{
+		oneFileSystem: oneFileSystem,
+		dev:           root.Dev,
+		sem:           sem,
+		seen:          map[[2]uint64]bool{},
+	}
+	w.add(info, root)
+	w.walk(path)
+	w.wg.Wait()
+
+	return Usage{Bytes: w.bytes.Load(), Blocks: w.blocks.Load(), Errors: w.errs}
+}
+
+type walker struct {
+	oneFileSystem bool
+	dev           uint64
+	sem           chan struct{}
+	wg            sync.WaitGroup
+
+	mu   sync.Mutex
+	seen map[[2]uint64]bool
+	errs []error
+
+	bytes  atomic.Int64
+	blocks atomic.Int64
+}
+
+/*Adds up the entries of one directory. Subdirectories are handed to a new
+goroutine while a slot is free and walked inline otherwise, so a busy walker
+never waits on itself*/
+func (w *walker) walk(path string) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		w.fail(err)
+		return
+	}
+
+	for _, entry := range entries {
+		// the raw stat is enough here, CreateFileInfo would also read links and xattrs
+		info, err := entry.Info()
+		if err != nil {
+			w.fail(err)
+			continue
+		}
+		stat, ok := info.Sys().(*syscall.Stat_t)
+		if !ok || (w.oneFileSystem && stat.Dev != w.dev) {
+			continue
+		}
+		if !w.add(info, stat) || !info.IsDir() {
+			continue
+		}
+		dir := path + "/" + info.Name()
+
+		select {
+		case w.sem <- struct{}{}:
+			w.wg.Add(1)
+			go func(dir string) {
+				defer w.wg.Done()
+				defer func() { <-w.sem }()
+				w.walk(dir)
+			}(dir)
+		default:
+			w.walk(dir)
+		}
+	}
+}
+
+func (w *walker) fail(err error) {
+	w.mu.Lock()
+	w.errs = append(w.errs, err)
+	w.mu.Unlock()
+}
+
+// Counts an entry, unless it is a hard link that was already counted.
+func (w *walker) add(info os.FileInfo, stat *syscall.Stat_t) bool {
+	if stat.Nlink > 1 && !info.IsDir() {
+		key := [2]uint64{stat.Dev, stat.Ino}
+		w.mu.Lock()
+		seen := w.seen[key]
+		w.seen[key] = true
+		w.mu.Unlock()
+		if seen {
+			return false
+		}
+	}
+
+	w.bytes.Add(info.Size())
+	w.blocks.Add(stat.Blocks / 2)
+	return true
+}
@@ -4,9 +4,9 @@ import (
 	"fmt"
 	"os"
 	"strings"
-	"syscall"
 
 	T "my-ls-1/cmd/terminal/lsOptions"
+	A "my-ls-1/internal/audit"
 	FI "my-ls-1/pkg/fileinfo"
 	OP "my-ls-1/pkg/options"
 	U "my-ls-1/pkg/utils"
@@ -20,33 +20,16 @@ func ListSingleFile(path string, options OP.Options) {
 		return
 	}
 
-	file := FI.FileInfo{
-		Name:    fileInfo.Name(),
-		Size:    fileInfo.Size(),
-		Mode:    fileInfo.Mode(),
-		ModTime: fileInfo.ModTime(),
-		IsDir:   fileInfo.IsDir(),
-		IsLink:  fileInfo.Mode()&os.ModeSymlink != 0,
-	}
-
-	if file.IsLink {
-		linkTarget, err := os.Readlink(path)
-		if err == nil {
-			file.LinkTarget = linkTarget
-		}
-	}
-
-	if stat, ok := fileInfo.Sys().(*syscall.Stat_t); ok {
-		file.Nlink = stat.Nlink
-		file.Uid = stat.Uid
-		file.Gid = stat.Gid
-		file.Rdev = stat.Rdev
-	}
+	file := FI.CreateFileInfo(T.Dir(path), fileInfo)
+	audit([]FI.FileInfo{file}, options)
 
 	if options.LongFormat {
 		U.PrintLongFormat([]FI.FileInfo{file}, options)
 	} else {
-		fmt.Println(U.FormatFileName(file, options))
+		fmt.Println(U.ShortEntries([]FI.FileInfo{file}, options)[0])
+		if options.Xattrs {
+			U.PrintXattrs(file)
+		}
 	}
 }
 
@@ -57,16 +40,33 @@ func ListDir(path string, options OP.Options) {
 		fmt.Printf("ls: cannot access '%s': %v\n", path, err)
 		return
 	}
+	audit(files, options)
 	U.PrintFiles(files, options)
+
+	if options.Summary {
+		U.PrintSummary("summary", U.Summarize(files))
+	}
 }
 
 //The function to list files and directories recursively
 func ListRecursive(path string, options OP.Options) {
+	var total U.Summary
+	listRecursive(path, options, &total)
+
+	if options.Summary {
+		U.PrintSummary("grand total", total)
+	}
+}
+
+/*Lists one directory and then every directory below it. The entries of each
+listing are added to total for the --summary grand total*/
+func listRecursive(path string, options OP.Options, total *U.Summary) {
 	if path == "." {
 		var NewPath string
 
-		fmt.Printf("%s:\n", path)
+		fmt.Printf("%s:\n", U.QuoteName(path, options))
 		files, _ := T.ReadDirectory(path, options)
+		audit(files, options)
 
 		if options.LongFormat {
 			if options.ShowHidden {
@@ -82,9 +82,18 @@ func ListRecursive(path string, options OP.Options) {
 			}
 		}
 
+		if options.Summary {
+			summary := U.Summarize(FilterHidden(files))
+			if options.ShowHidden {
+				summary = U.Summarize(files)
+			}
+			U.PrintSummary("summary", summary)
+			total.Merge(summary)
+		}
+
 		fmt.Println()
 
-		for _, file := range files {
+		for _, file := range subdirectories(path, files, options) {
 			if file.IsDir {
 
 				if strings.HasSuffix(path, "/") {
@@ -93,15 +102,16 @@ func ListRecursive(path string, options OP.Options) {
 					NewPath = fmt.Sprintf("%s/%s", path, file.Name)
 				}
 
-				ListRecursive(NewPath, options)
+				listRecursive(NewPath, options, total)
 			}
 		}
 	}
 	if !strings.HasSuffix(path, ".") && !strings.HasSuffix(path, "..") {
 
 		var NewPath string
-		fmt.Printf("%s:\n", path)
+		fmt.Printf("%s:\n", U.QuoteName(path, options))
 		files, _ := T.ReadDirectory(path, options)
+		audit(files, options)
 
 		if options.LongFormat {
 			if options.ShowHidden {
@@ -117,10 +127,19 @@ func ListRecursive(path string, options OP.Options) {
 			}
 		}
 
+		if options.Summary {
+			summary := U.Summarize(FilterHidden(files))
+			if options.ShowHidden {
+				summary = U.Summarize(files)
+			}
+			U.PrintSummary("summary", summary)
+			total.Merge(summary)
+		}
+
 		fmt.Println()
 
 		// open  a loop to update the path for every entry
-		for _, file := range files {
+		for _, file := range subdirectories(path, files, options) {
 			if file.IsDir {
 
 				if strings.HasSuffix(path, "/") {
@@ -129,12 +148,36 @@ func ListRecursive(path string, options OP.Options) {
 					NewPath = fmt.Sprintf("%s/%s", path, file.Name)
 				}
 
-				ListRecursive(NewPath, options)
+				listRecursive(NewPath, options, total)
 			}
 		}
 	}
 }
 
+/*Returns the entries to descend into with -R. Filters only decide what is
+shown, so with a filter set the directory is read again without it*/
+func subdirectories(path string, files []FI.FileInfo, options OP.Options) []FI.FileInfo {
+	if options.Filter == nil {
+		return files
+	}
+	unfiltered := options
+	unfiltered.Filter = nil
+	unfiltered.DiskUsage = ""
+	all, _ := T.ReadDirectory(path, unfiltered)
+	return all
+}
+
+//Hands the entries a listing shows to --audit
+func audit(files []FI.FileInfo, options OP.Options) {
+	if !options.Audit {
+		return
+	}
+	if !options.ShowHidden {
+		files = FilterHidden(files)
+	}
+	A.Check(files)
+}
+
 //The function will eliminate the directories and file that start in a period(.)
 func FilterHidden(entries []FI.FileInfo) []FI.FileInfo {
 	var filtered []FI.FileInfo
@@ -0,0 +1,286 @@
+package sort
+
+import (
+	"os"
+	"strings"
+	"unicode"
+)
+
+// How names are compared, chosen from the environment by InitCollation.
+const (
+	collateDefault = iota // no locale configured: CompareFilenamesAlphanumeric
+	collateBytes          // C and POSIX locales: strict byte order
+	collateUnicode        // any other locale: multi-level Unicode collation
+)
+
+var (
+	collation = collateDefault
+	tailoring map[rune]tailoredLetter
+)
+
+// A tailoredLetter sorts as a letter of its own, rank places after base.
+type tailoredLetter struct {
+	base rune
+	rank int
+}
+
+/*Letters that a language treats as separate letters of the alphabet instead of
+an accented variant of their base letter, keyed by language code*/
+var tailorings = map[string]map[rune]tailoredLetter{
+	"sv": {'å': {'z', 1}, 'ä': {'z', 2}, 'ö': {'z', 3}},
+	"fi": {'å': {'z', 1}, 'ä': {'z', 2}, 'ö': {'z', 3}},
+	"da": {'æ': {'z', 1}, 'ø': {'z', 2}, 'å': {'z', 3}},
+	"nb": {'æ': {'z', 1}, 'ø': {'z', 2}, 'å': {'z', 3}},
+	"nn": {'æ': {'z', 1}, 'ø': {'z', 2}, 'å': {'z', 3}},
+	"no": {'æ': {'z', 1}, 'ø': {'z', 2}, 'å': {'z', 3}},
+	"es": {'ñ': {'n', 1}},
+	"pl": {'ą': {'a', 1}, 'ć': {'c', 1}, 'ę': {'e', 1}, 'ł': {'l', 1}, 'ń': {'n', 1},
+		'ó': {'o', 1}, 'ś': {'s', 1}, 'ź': {'z', 1}, 'ż': {'z', 2}},
+	"cs": {'č': {'c', 1}, 'ř': {'r', 1}, 'š': {'s', 1}, 'ž': {'z', 1}},
+	"tr": {'ç': {'c', 1}, 'ğ': {'g', 1}, 'ı': {'h', 1}, 'ö': {'o', 1}, 'ş': {'s', 1},
+		'ü': {'u', 1}},
+}
+
+/*This function reads LC_ALL, LC_COLLATE and LANG, in that order of precedence,
+and selects how names are compared for the rest of the run*/
+func InitCollation() {
+	for _, name := range []string{"LC_ALL", "LC_COLLATE", "LANG"} {
+		if locale := os.Getenv(name); locale != "" {
+			SetLocale(locale)
+			return
+		}
+	}
+	SetLocale("")
+}
+
+/*Selects the collation for a locale name such as "sv_SE.UTF-8". "C" and "POSIX"
+compare bytes, an empty name keeps the historical alphanumeric comparison*/
+func SetLocale(locale string) {
+	lang, _, _ := strings.Cut(locale, ".")
+	lang, _, _ = strings.Cut(lang, "@")
+
+	switch lang {
+	case "":
+		collation = collateDefault
+	case "C", "POSIX":
+		collation = collateBytes
+	default:
+		collation = collateUnicode
+		language, _, _ := strings.Cut(lang, "_")
+		tailoring = tailorings[strings.ToLower(language)]
+	}
+}
+
+//Compares two names with the collation selected for the run
+func CompareNames(a, b string) int {
+	switch collation {
+	case collateBytes:
+		return strings.Compare(a, b)
+	case collateUnicode:
+		return Collate(a, b)
+	}
+
+	if CompareFilenamesAlphanumeric(a, b) {
+		return -1
+	}
+	if CompareFilenamesAlphanumeric(b, a) {
+		return 1
+	}
+	return 0
+}
+
+/*One collation element per letter or digit. Punctuation, symbols and spaces
+produce none and only decide between names that are otherwise equal*/
+type collationElement struct {
+	primary   int // the case folded base letter, or its tailored position
+	secondary int // zero for a plain letter, otherwise the ranks of its marks
+	tertiary  int // one for uppercase
+}
+
+/*A collationKey holds everything Collate needs about a name: its elements and
+the name in decomposed form, which breaks the remaining ties so that the
+precomposed and decomposed spellings of a name compare equal*/
+type collationKey struct {
+	elems      []collationElement
+	decomposed string
+}
+
+/*Keys of the names being sorted, filled by PrepareCollation so that each name
+is decomposed once per sort instead of once per comparison*/
+var collationKeys map[string]collationKey
+
+/*compositions maps a decomposed letter back to its precomposed form, so that
+tailorings also apply to names spelled with combining marks*/
+var compositions = func() map[string]rune {
+	composed := make(map[string]rune, len(decompositions))
+	for r, d := range decompositions {
+		composed[d] = r
+	}
+	return composed
+}()
+
+// Ranks a secondary weight after every mark: an expansion such as ß or æ.
+var expansionRank = len(markRanks) + 2
+
+/*Computes the collation keys of names ahead of a sort. Comparisons of names
+that were not prepared compute their keys on the fly*/
+func PrepareCollation(names []string) {
+	if collation != collateUnicode {
+		return
+	}
+	collationKeys = make(map[string]collationKey, len(names))
+	for _, name := range names {
+		collationKeys[name] = collationKeyFor(name)
+	}
+}
+
+//Drops the keys computed by PrepareCollation once the sort is done
+func ResetCollation() {
+	collationKeys = nil
+}
+
+/*Compares two names level by level: base letters first, then accents, then
+case. Names still equal after that are ordered by their decomposed bytes*/
+func Collate(a, b string) int {
+	aKey, ok := collationKeys[a]
+	if !ok {
+		aKey = collationKeyFor(a)
+	}
+	bKey, ok := collationKeys[b]
+	if !ok {
+		bKey = collationKeyFor(b)
+	}
+
+	for level := 0; level < 3; level++ {
+		for i := 0; i < len(aKey.elems) && i < len(bKey.elems); i++ {
+			if c := aKey.elems[i].weight(level) - bKey.elems[i].weight(level); c != 0 {
+				return c
+			}
+		}
+		if len(aKey.elems) != len(bKey.elems) {
+			return len(aKey.elems) - len(bKey.elems)
+		}
+	}
+
+	return strings.Compare(aKey.decomposed, bKey.decomposed)
+}
+
+func (e collationElement) weight(level int) int {
+	switch level {
+	case 0:
+		return e.primary
+	case 1:
+		return e.secondary
+	}
+	return e.tertiary
+}
+
+func collationKeyFor(s string) collationKey {
+	runes := decompose(s)
+	elems := make([]collationElement, 0, len(runes))
+
+	for i := 0; i < len(runes); {
+		letter := runes[i]
+		end := i + 1
+		for end < len(runes) && unicode.Is(unicode.Mn, runes[end]) {
+			end++
+		}
+		marks := runes[i+1 : end]
+		i = end
+
+		tertiary := 0
+		if unicode.IsUpper(letter) {
+			tertiary = 1
+		}
+
+		if t, rest, ok := tailored(letter, marks); ok {
+			elems = append(elems, collationElement{primaryWeight(t.base) + t.rank, markWeight(rest), tertiary})
+			continue
+		}
+
+		if expansion, ok := expansions[letter]; ok {
+			for n, r := range expansion {
+				elem := collationElement{primaryWeight(foldRune(r)), 0, tertiary}
+				if n == 0 {
+					elem.secondary = expansionRank
+				}
+				elems = append(elems, elem)
+			}
+			continue
+		}
+
+		// a mark with no letter before it, punctuation, symbols and spaces
+		if !unicode.IsLetter(letter) && !unicode.IsDigit(letter) {
+			continue
+		}
+		elems = append(elems, collationElement{primaryWeight(foldRune(letter)), markWeight(marks), tertiary})
+	}
+
+	return collationKey{elems, string(runes)}
+}
+
+//Spells a name with every precomposed letter replaced by its decomposition
+func decompose(s string) []rune {
+	runes := make([]rune, 0, len(s))
+	for _, r := range s {
+		if d, ok := decompositions[r]; ok {
+			runes = append(runes, []rune(d)...)
+		} else {
+			runes = append(runes, r)
+		}
+	}
+	return runes
+}
+
+/*Looks up the tailoring of a letter together with as many of its marks as
+compose with it, and returns the marks left over*/
+func tailored(letter rune, marks []rune) (tailoredLetter, []rune, bool) {
+	if tailoring == nil {
+		return tailoredLetter{}, nil, false
+	}
+	for n := len(marks); n >= 0; n-- {
+		composed := letter
+		if n > 0 {
+			r, ok := compositions[string(letter)+string(marks[:n])]
+			if !ok {
+				continue
+			}
+			composed = r
+		}
+		if t, ok := tailoring[foldRune(composed)]; ok {
+			return t, marks[n:], true
+		}
+	}
+	return tailoredLetter{}, nil, false
+}
+
+/*Combines the ranks of a letter's marks, in the order they are written, into
+its secondary weight*/
+func markWeight(marks []rune) int {
+	weight := 0
+	for _, mark := range marks {
+		rank, ok := markRanks[mark]
+		if !ok {
+			rank = len(markRanks) + 1
+		}
+		weight = weight*64 + rank
+	}
+	return weight
+}
+
+// Leaves room after every letter for the tailored letters that follow it.
+func primaryWeight(r rune) int {
+	return int(r) * 16
+}
+
+/*Case folds a rune: every rune of a case orbit (A, a; K, k, the Kelvin sign;
+Σ, σ, ς) maps to the same lowercase representative*/
+func foldRune(r rune) rune {
+	smallest := r
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		if f < smallest {
+			smallest = f
+		}
+	}
+	return unicode.ToLower(smallest)
+}
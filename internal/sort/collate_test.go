@@ -0,0 +1,43 @@
+package sort
+
+import "testing"
+
+func TestCollate(t *testing.T) {
+	tests := []struct {
+		locale string
+		a, b   string
+		want   int
+	}{
+		// base letters decide first, then accents, then case
+		{"en_US.UTF-8", "zebra", "Zeta", -1},
+		{"en_US.UTF-8", "émile", "Émile", -1},
+		{"en_US.UTF-8", "Émile", "emilf", -1},
+		{"en_US.UTF-8", "emile", "émile", -1},
+		// accents are ranked, not ordered by code point
+		{"en_US.UTF-8", "é", "è", -1},
+		{"en_US.UTF-8", "è", "ê", -1},
+		// precomposed and decomposed spellings are the same name
+		{"en_US.UTF-8", "émile", "e\u0301mile", 0},
+		{"en_US.UTF-8", "Ångström", "A\u030angstro\u0308m", 0},
+		// punctuation only decides between otherwise equal names
+		{"en_US.UTF-8", "_under", "alpha", 1},
+		{"en_US.UTF-8", "ss", "ß", -1},
+		{"en_US.UTF-8", "ß", "st", -1},
+		// tailored letters, in either spelling
+		{"sv_SE.UTF-8", "zz", "ånge", -1},
+		{"sv_SE.UTF-8", "zz", "a\u030ange", -1},
+		{"sv_SE.UTF-8", "ånge", "änge", -1},
+		{"da_DK.UTF-8", "zz", "øre", -1},
+		{"en_US.UTF-8", "øre", "pre", -1},
+	}
+	defer SetLocale("")
+	for _, tt := range tests {
+		SetLocale(tt.locale)
+		if got := sign(Collate(tt.a, tt.b)); got != tt.want {
+			t.Errorf("%s: Collate(%q, %q) = %d, want %d", tt.locale, tt.a, tt.b, got, tt.want)
+		}
+		if got := sign(Collate(tt.b, tt.a)); got != -tt.want {
+			t.Errorf("%s: Collate(%q, %q) = %d, want %d", tt.locale, tt.b, tt.a, got, -tt.want)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package sort
+
+import (
+	"os"
+	"strings"
+
+	FI "my-ls-1/pkg/fileinfo"
+	OP "my-ls-1/pkg/options"
+	W "my-ls-1/pkg/utils/width"
+)
+
+/*A comparator returns a negative number when a sorts before b, a positive
+number when it sorts after and zero when the key cannot tell them apart*/
+type comparator func(a, b FI.FileInfo, options OP.Options) int
+
+// comparators is the table every --sort key is resolved through.
+var comparators = map[string]comparator{
+	"none":      func(a, b FI.FileInfo, options OP.Options) int { return 0 },
+	"name":      compareName,
+	"size":      compareSize,
+	"time":      compareTime,
+	"extension": compareExtension,
+	"version":   compareVersion,
+	"width":     compareWidth,
+	"inode":     compareInode,
+	"owner":     compareOwner,
+	"group":     compareGroup,
+	"type":      compareType,
+}
+
+/*Returns the chain of keys to sort by. An explicit --sort, -X, -v or -U wins,
+otherwise -t and -S keep their historical meaning and the name is the default*/
+func SortKeysFor(options OP.Options) []OP.SortKey {
+	if len(options.SortKeys) > 0 {
+		return options.SortKeys
+	}
+	if options.SortByTime {
+		return []OP.SortKey{{Name: "time"}}
+	}
+	if options.SortBySize {
+		return []OP.SortKey{{Name: "size"}}
+	}
+	return []OP.SortKey{{Name: "name"}}
+}
+
+/*Compares two entries key by key. The first key that tells them apart decides,
+and the name comparison breaks any remaining tie*/
+func CompareFiles(a, b FI.FileInfo, keys []OP.SortKey, options OP.Options) int {
+	for _, key := range keys {
+		compare, ok := comparators[key.Name]
+		if !ok {
+			continue
+		}
+		if c := compare(a, b, options); c != 0 {
+			if key.Reverse {
+				return -c
+			}
+			return c
+		}
+	}
+	return compareName(a, b, options)
+}
+
+// Names collate according to the locale, see InitCollation.
+func compareName(a, b FI.FileInfo, options OP.Options) int {
+	return CompareNames(a.Name, b.Name)
+}
+
+// Largest first, like -S.
+func compareSize(a, b FI.FileInfo, options OP.Options) int {
+	return compareInt64(b.Size, a.Size)
+}
+
+// Newest first, like -t, using the field chosen with --time.
+func compareTime(a, b FI.FileInfo, options OP.Options) int {
+	return b.Time(options.TimeField).Compare(a.Time(options.TimeField))
+}
+
+// Entries without an extension come first, the rest by extension.
+func compareExtension(a, b FI.FileInfo, options OP.Options) int {
+	return strings.Compare(extension(a.Name), extension(b.Name))
+}
+
+func compareVersion(a, b FI.FileInfo, options OP.Options) int {
+	return CompareVersions(a.Name, b.Name)
+}
+
+// Narrowest name first, in terminal cells.
+func compareWidth(a, b FI.FileInfo, options OP.Options) int {
+	return compareInt64(int64(W.DisplayWidth(a.Name)), int64(W.DisplayWidth(b.Name)))
+}
+
+func compareInode(a, b FI.FileInfo, options OP.Options) int {
+	return compareUint64(a.Ino, b.Ino)
+}
+
+func compareOwner(a, b FI.FileInfo, options OP.Options) int {
+	return strings.Compare(FI.UserName(a.Uid), FI.UserName(b.Uid))
+}
+
+func compareGroup(a, b FI.FileInfo, options OP.Options) int {
+	return strings.Compare(FI.GroupName(a.Gid), FI.GroupName(b.Gid))
+}
+
+// Directories first, then symlinks, regular files and the special files.
+func compareType(a, b FI.FileInfo, options OP.Options) int {
+	return compareInt64(int64(typeRank(a)), int64(typeRank(b)))
+}
+
+func typeRank(file FI.FileInfo) int {
+	switch {
+	case file.IsDir:
+		return 0
+	case file.IsLink:
+		return 1
+	case file.Mode.IsRegular():
+		return 2
+	case file.Mode&os.ModeNamedPipe != 0:
+		return 3
+	case file.Mode&os.ModeSocket != 0:
+		return 4
+	case file.Mode&os.ModeDevice != 0:
+		return 5
+	default:
+		return 6
+	}
+}
+
+//Returns what follows the last period of a name, or "" when there is none
+func extension(name string) string {
+	i := strings.LastIndex(name, ".")
+	if i == -1 {
+		return ""
+	}
+	return name[i+1:]
+}
+
+func compareInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareUint64(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -1,34 +1,68 @@
 package sort
 
 import (
-	"strconv"
+	"strings"
 
 	FI "my-ls-1/pkg/fileinfo"
 	OP "my-ls-1/pkg/options"
 )
 
 /*This function will take an array of fileInfo and sort them based on the conditions
-set by the flags passed on the command line. If the option for reverse was set to true
-this action takes place right after the sorting*/
+set by the flags passed on the command line. Every key of the chain is looked up in
+the comparator table and ties fall through to the next key, then to the name. If the
+option for reverse was set to true this action takes place right after the sorting,
+and directory grouping is applied last. With -U nothing is reordered, not even
+the directories, like GNU ls*/
 func SortFiles(files []FI.FileInfo, options OP.Options) {
-	CustomSort(files, func(i, j int) bool {
+	if Unsorted(options) {
+		return
+	}
 
-		if options.SortByTime {
-			if !files[i].ModTime.Equal(files[j].ModTime) {
-				return files[i].ModTime.After(files[j].ModTime)
-			}
-		} else if options.SortBySize {
-			if files[i].Size != files[j].Size {
-				return files[i].Size > files[j].Size
-			}
-		}
+	names := make([]string, len(files))
+	for i, file := range files {
+		names[i] = file.Name
+	}
+	PrepareCollation(names)
+	defer ResetCollation()
 
-		return CompareFilenamesAlphanumeric(files[i].Name, files[j].Name)
+	keys := SortKeysFor(options)
+	CustomSort(files, func(i, j int) bool {
+		return CompareFiles(files[i], files[j], keys, options) < 0
 	})
 
 	if options.Reverse {
 		ReverseSlice(files)
 	}
+
+	if options.GroupDirs != "" {
+		GroupDirectories(files, options)
+	}
+}
+
+//Reports whether -U or --sort=none keeps entries in directory order
+func Unsorted(options OP.Options) bool {
+	keys := SortKeysFor(options)
+	return len(keys) == 1 && keys[0].Name == "none"
+}
+
+/*Moves the directories in front of (or behind, for --dirs-last) everything else
+while keeping the order the sort produced inside each group. It runs after the
+reversal so -r never swaps the groups themselves*/
+func GroupDirectories(files []FI.FileInfo, options OP.Options) {
+	var dirs, others []FI.FileInfo
+	for _, file := range files {
+		if file.IsDirLike(options.LinksAsDir) {
+			dirs = append(dirs, file)
+		} else {
+			others = append(others, file)
+		}
+	}
+
+	if options.GroupDirs == "last" {
+		dirs, others = others, dirs
+	}
+	n := copy(files, dirs)
+	copy(files[n:], others)
 }
 
 // CustomSort sorts a slice using a custom less function
@@ -84,12 +118,13 @@ func CompareFilenamesAlphanumeric(a, b string) bool {
 			aNum, aEnd := ExtractNumber(aRunes[i:])
 			bNum, bEnd := ExtractNumber(bRunes[j:])
 
-			if aNum != bNum {
-				return aNum < bNum
+			if c := compareDigitRuns(aNum, bNum); c != 0 {
+				return c < 0
 			}
 
 			i += aEnd
 			j += bEnd
+			continue
 		} else {
 			aLower := ToLower(aRunes[i])
 			bLower := ToLower(bRunes[j])
@@ -109,16 +144,14 @@ func IsAlphanumeric(r rune) bool {
 	return IsLetter(r) || IsDigit(r)
 }
 
-//This function gets the int from the rune provided
-func ExtractNumber(runes []rune) (int, int) {
-	num := 0
+/*This function gets the run of digits at the start of the runes provided and its
+length. The digits are kept as text so runs of any length can be compared*/
+func ExtractNumber(runes []rune) (string, int) {
 	i := 0
 	for i < len(runes) && IsDigit(runes[i]) {
-		digit, _ := strconv.Atoi(string(runes[i]))
-		num = num*10 + digit
 		i++
 	}
-	return num, i
+	return string(runes[:i]), i
 }
 
 //checks if the rune is is a digit(0 -9)
@@ -159,3 +192,13 @@ func containsRune(s string, r rune) bool {
 	}
 	return false
 }
+
+//Compares two runs of digits numerically without converting them to integers
+func compareDigitRuns(a, b string) int {
+	a = strings.TrimLeft(a, "0")
+	b = strings.TrimLeft(b, "0")
+	if len(a) != len(b) {
+		return len(a) - len(b)
+	}
+	return strings.Compare(a, b)
+}
@@ -0,0 +1,130 @@
+package sort
+
+/*Compares two names the way GNU filevercmp does, which is what -v and
+--sort=version use. "." and ".." come first, then other hidden names. File
+suffixes such as ".tar.gz" are compared only when the rest of the names are
+equal, digit runs compare by numeric value whatever their length, and '~'
+sorts before everything, even the end of the name, so "1.0~rc1" < "1.0"*/
+func CompareVersions(a, b string) int {
+	if a == b {
+		return 0
+	}
+	if a == "" {
+		return -1
+	}
+	if b == "" {
+		return 1
+	}
+
+	if a[0] == '.' {
+		if b[0] != '.' {
+			return -1
+		}
+		switch {
+		case a == ".":
+			return -1
+		case b == ".":
+			return 1
+		case a == "..":
+			return -1
+		case b == "..":
+			return 1
+		}
+	} else if b[0] == '.' {
+		return 1
+	}
+
+	aPrefix := filePrefixLen(a)
+	bPrefix := filePrefixLen(b)
+
+	result := verrevcmp(a[:aPrefix], b[:bPrefix])
+	if result != 0 || (aPrefix == len(a) && bPrefix == len(b)) {
+		return result
+	}
+	return verrevcmp(a, b)
+}
+
+/*
+Returns the length of a name without its suffix, the longest trailing run
+matching (\.[A-Za-z~][A-Za-z0-9~]*)*
+*/
+func filePrefixLen(s string) int {
+	prefixLen := 0
+	for i := 0; ; {
+		if i == len(s) {
+			return prefixLen
+		}
+		i++
+		prefixLen = i
+		for i+1 < len(s) && s[i] == '.' && (isASCIILetter(s[i+1]) || s[i+1] == '~') {
+			for i += 2; i < len(s) && (isASCIILetter(s[i]) || IsDigit(rune(s[i])) || s[i] == '~'); i++ {
+			}
+		}
+	}
+}
+
+/*Compares alternating runs of non-digits and digits. Non-digits compare by
+versionOrder, digit runs by value, with leading zeros ignored*/
+func verrevcmp(a, b string) int {
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		firstDiff := 0
+		for (i < len(a) && !IsDigit(rune(a[i]))) || (j < len(b) && !IsDigit(rune(b[j]))) {
+			aOrder, bOrder := versionOrder(a, i), versionOrder(b, j)
+			if aOrder != bOrder {
+				return aOrder - bOrder
+			}
+			i++
+			j++
+		}
+
+		for i < len(a) && a[i] == '0' {
+			i++
+		}
+		for j < len(b) && b[j] == '0' {
+			j++
+		}
+
+		for i < len(a) && j < len(b) && IsDigit(rune(a[i])) && IsDigit(rune(b[j])) {
+			if firstDiff == 0 {
+				firstDiff = int(a[i]) - int(b[j])
+			}
+			i++
+			j++
+		}
+
+		if i < len(a) && IsDigit(rune(a[i])) {
+			return 1
+		}
+		if j < len(b) && IsDigit(rune(b[j])) {
+			return -1
+		}
+		if firstDiff != 0 {
+			return firstDiff
+		}
+	}
+	return 0
+}
+
+/*Weight of the byte at pos: the end of the string and digits weigh nothing,
+'~' sorts before them, letters next and every other byte after the letters*/
+func versionOrder(s string, pos int) int {
+	if pos >= len(s) {
+		return 0
+	}
+	c := s[pos]
+	switch {
+	case IsDigit(rune(c)):
+		return 0
+	case isASCIILetter(c):
+		return int(c)
+	case c == '~':
+		return -1
+	default:
+		return int(c) + 256
+	}
+}
+
+func isASCIILetter(c byte) bool {
+	return IsLetter(rune(c))
+}
@@ -0,0 +1,134 @@
+package sort
+
+import (
+	"strings"
+	"testing"
+)
+
+// The ordering of gnulib's tests/test-filevercmp.c, every name sorts before the ones after it.
+var filevercmpOrder = []string{
+	"",
+	".",
+	"..",
+	".0",
+	".9",
+	".A",
+	".Z",
+	".a~",
+	".a",
+	".b~",
+	".b",
+	".z",
+	".zz~",
+	".zz",
+	".zz.~1~",
+	".zz.0",
+	".\x01",
+	".\x01.txt",
+	".\x01x",
+	".\x01x\x01",
+	".\x01.0",
+	"0",
+	"9",
+	"A",
+	"Z",
+	"a~",
+	"a",
+	"a.b~",
+	"a.b",
+	"a.bc~",
+	"a.bc",
+	"a+",
+	"a.",
+	"a..a",
+	"a.+",
+	"b~",
+	"b",
+	"gcc-c++-10.fc9.tar.gz",
+	"gcc-c++-10.fc9.tar.gz.~1~",
+	"gcc-c++-10.fc9.tar.gz.~2~",
+	"gcc-c++-10.8.12-0.7rc2.fc9.tar.bz2",
+	"gcc-c++-10.8.12-0.7rc2.fc9.tar.bz2.~1~",
+	"glibc-2-0.1.beta1.fc10.rpm",
+	"glibc-common-5-0.2.beta2.fc9.ebuild",
+	"glibc-common-5-0.2b.deb",
+	"glibc-common-11b.ebuild",
+	"glibc-common-11-0.6rc2.ebuild",
+	"libstdc++-0.5.8.11-0.7rc2.fc10.tar.gz",
+	"libstdc++-4a.fc8.tar.gz",
+	"libstdc++-4.10.4.20040204svn.rpm",
+	"libstdc++-devel-3.fc8.ebuild",
+	"libstdc++-devel-3a.fc9.tar.gz",
+	"libstdc++-devel-8.fc8.deb",
+	"libstdc++-devel-8.6.2-0.4b.fc8",
+	"nss_ldap-1-0.2b.fc9.tar.bz2",
+	"nss_ldap-1-0.6rc2.fc8.tar.gz",
+	"nss_ldap-1.0-0.1a.tar.gz",
+	"nss_ldap-10beta1.fc8.tar.gz",
+	"nss_ldap-10.11.8.6.20040204cvs.fc10.ebuild",
+	"z",
+	"zz~",
+	"zz",
+	"zz.~1~",
+	"zz.0",
+	"zz.0.txt",
+	"\x01",
+	"\x01.txt",
+	"\x01x",
+	"\x01x\x01",
+	"\x01.0",
+	"#\x01.b#",
+	"#.b#",
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
+
+func TestCompareVersionsGnulibOrder(t *testing.T) {
+	for i, a := range filevercmpOrder {
+		for j, b := range filevercmpOrder {
+			want := sign(i - j)
+			if got := sign(CompareVersions(a, b)); got != want {
+				t.Errorf("CompareVersions(%q, %q) = %d, want %d", a, b, got, want)
+			}
+		}
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	long := strings.Repeat("9", 25)
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		// digit runs longer than any integer type compare by value
+		{"file" + long, "file1" + long, -1},
+		{"file" + long + "8", "file" + long + "9", -1},
+		{"v000" + long, "v" + long, 0},
+		{"v0" + long + ".txt", "v" + long + ".txt", 0},
+		// '~' sorts before the end of the name
+		{"1.0~rc1", "1.0", -1},
+		{"1.0~rc1", "1.0~rc2", -1},
+		{"foo~", "foo", -1},
+		// suffixes only decide when the rest of the names are equal
+		{"foo-1.2.tar.gz", "foo-1.10.tar.gz", -1},
+		{"foo-1.10.tar.gz", "foo-1.2.tar.bz2", 1},
+		{"foo-1.2.tar.bz2", "foo-1.2.tar.gz", -1},
+		{"foo.tar.gz", "foo.tar.gz.~1~", -1},
+	}
+	for _, tt := range tests {
+		if got := sign(CompareVersions(tt.a, tt.b)); got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := sign(CompareVersions(tt.b, tt.a)); got != -tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.b, tt.a, got, -tt.want)
+		}
+	}
+}
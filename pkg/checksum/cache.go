@@ -0,0 +1,164 @@
+package checksum
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// Files are cached per algorithm and inode.
+type cacheKey struct {
+	algorithm string
+	dev, ino  uint64
+}
+
+/*A cached checksum stays valid as long as the inode keeps the size and
+modification time it had when it was hashed*/
+type cacheEntry struct {
+	size  int64
+	mtime int64 // nanoseconds
+	sum   string
+	path  string // absolute path the file was hashed under
+}
+
+var (
+	cacheMu   sync.Mutex
+	cache     = map[cacheKey]cacheEntry{}
+	cachePath string
+	dirty     bool // a checksum was computed or an entry invalidated
+)
+
+/*This function reads the checksum cache kept at path by an earlier run. The
+file does not need to exist, SaveCache creates it. Every line holds the
+algorithm, device, inode, size, modification time, checksum and path of one
+file*/
+func LoadCache(path string) error {
+	cachePath = path
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if key, entry, ok := parseCacheLine(scanner.Text()); ok {
+			cache[key] = entry
+		}
+	}
+	return scanner.Err()
+}
+
+// The path is last, it may contain spaces.
+func parseCacheLine(line string) (cacheKey, cacheEntry, bool) {
+	var key cacheKey
+	var entry cacheEntry
+
+	fields := strings.SplitN(line, " ", 7)
+	if len(fields) < 6 {
+		return key, entry, false
+	}
+	var errs [4]error
+	key.algorithm = fields[0]
+	key.dev, errs[0] = strconv.ParseUint(fields[1], 10, 64)
+	key.ino, errs[1] = strconv.ParseUint(fields[2], 10, 64)
+	entry.size, errs[2] = strconv.ParseInt(fields[3], 10, 64)
+	entry.mtime, errs[3] = strconv.ParseInt(fields[4], 10, 64)
+	for _, err := range errs {
+		if err != nil {
+			return key, entry, false
+		}
+	}
+	entry.sum = fields[5]
+	if len(fields) == 7 {
+		entry.path = fields[6]
+	}
+	return key, entry, true
+}
+
+/*Writes the cache back when this run computed a checksum or found a cached
+one out of date. Entries of files that were not listed are kept unless their
+file is confirmed gone: its path no longer exists or names another inode. The
+file is replaced in one rename so an interrupted run never leaves half a cache
+behind*/
+func SaveCache() error {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+
+	if cachePath == "" || !dirty {
+		return nil
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(cachePath), ".ls-checksums-*")
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(tmp)
+	for key, entry := range cache {
+		if gone(key, entry) {
+			continue
+		}
+		fmt.Fprintf(w, "%s %d %d %d %d %s %s\n", key.algorithm, key.dev, key.ino, entry.size, entry.mtime, entry.sum, entry.path)
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	dirty = false
+	return os.Rename(tmp.Name(), cachePath)
+}
+
+// Reports whether the file a cache entry was made for no longer exists.
+func gone(key cacheKey, entry cacheEntry) bool {
+	if entry.path == "" {
+		return false
+	}
+	info, err := os.Lstat(entry.path)
+	if os.IsNotExist(err) {
+		return true
+	}
+	if err != nil {
+		return false
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	return ok && (stat.Dev != key.dev || stat.Ino != key.ino)
+}
+
+/*Returns the cached checksum of an inode when its size and modification time
+still match. An entry that does not match any more is dropped*/
+func cached(key cacheKey, size, mtime int64) (string, bool) {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+
+	entry, ok := cache[key]
+	if !ok {
+		return "", false
+	}
+	if entry.size != size || entry.mtime != mtime {
+		delete(cache, key)
+		dirty = cachePath != ""
+		return "", false
+	}
+	return entry.sum, true
+}
+
+func store(key cacheKey, entry cacheEntry) {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	cache[key] = entry
+	dirty = cachePath != ""
+}
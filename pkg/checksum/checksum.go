@@ -0,0 +1,105 @@
+package checksum
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+
+	FI "my-ls-1/pkg/fileinfo"
+)
+
+// algorithms are the hashes --checksum can compute.
+var algorithms = map[string]func() hash.Hash{
+	"sha256": sha256.New,
+	"sha1":   sha1.New,
+	"md5":    md5.New,
+	"crc32":  func() hash.Hash { return crc32.NewIEEE() },
+	"xxhash": func() hash.Hash { return newXXHash64() },
+}
+
+// ErrTooLarge is returned for files above the size cap.
+var ErrTooLarge = errors.New("file too large")
+
+//Reports whether --checksum knows the algorithm
+func Supported(algorithm string) bool {
+	_, ok := algorithms[algorithm]
+	return ok
+}
+
+/*A Result is the checksum of one entry. Sum is empty for anything that is not
+a regular file, Err is set when the file could not be read or was larger than
+the size cap*/
+type Result struct {
+	Sum string
+	Err error
+}
+
+/*This function hashes the contents of every regular file of a listing with the
+given algorithm, several files at a time. Files larger than maxSize bytes are
+skipped with ErrTooLarge unless maxSize is 0. Sums found in the cache, see
+LoadCache, are not computed again*/
+func Files(files []FI.FileInfo, algorithm string, maxSize int64) []Result {
+	results := make([]Result, len(files))
+	sem := make(chan struct{}, runtime.NumCPU())
+	var wg sync.WaitGroup
+
+	for i, file := range files {
+		if !file.Mode.IsRegular() {
+			continue
+		}
+		if maxSize > 0 && file.Size > maxSize {
+			results[i].Err = ErrTooLarge
+			continue
+		}
+		key := cacheKey{algorithm, file.Dev, file.Ino}
+		if sum, ok := cached(key, file.Size, file.ModTime.UnixNano()); ok {
+			results[i].Sum = sum
+			continue
+		}
+
+		wg.Add(1)
+		go func(result *Result, file FI.FileInfo, key cacheKey) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			result.Sum, result.Err = Sum(file.Path, algorithm)
+			if result.Err == nil {
+				path, _ := filepath.Abs(file.Path)
+				store(key, cacheEntry{file.Size, file.ModTime.UnixNano(), result.Sum, path})
+			}
+		}(&results[i], file, key)
+	}
+	wg.Wait()
+
+	return results
+}
+
+//Returns the hex checksum of the file at path
+func Sum(path, algorithm string) (string, error) {
+	newHash, ok := algorithms[algorithm]
+	if !ok {
+		return "", errors.New("unknown checksum algorithm " + algorithm)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := newHash()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
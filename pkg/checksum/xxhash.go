@@ -0,0 +1,123 @@
+package checksum
+
+import (
+	"encoding/binary"
+	"hash"
+	"math/bits"
+)
+
+// The five primes of XXH64.
+const (
+	prime1 uint64 = 11400714785074694791
+	prime2 uint64 = 14029467366897019727
+	prime3 uint64 = 1609587929392839161
+	prime4 uint64 = 9650029242287828579
+	prime5 uint64 = 2870177450012600261
+)
+
+/*xxHash64 is the 64 bit xxHash with a zero seed, as printed by xxhsum -H1.
+Input is consumed in 32 byte stripes by four accumulators, the tail is kept
+in buf until the next Write or Sum*/
+type xxHash64 struct {
+	v     [4]uint64
+	total uint64
+	buf   [32]byte
+	n     int // bytes waiting in buf
+}
+
+func newXXHash64() hash.Hash64 {
+	h := &xxHash64{}
+	h.Reset()
+	return h
+}
+
+func (h *xxHash64) Reset() {
+	// the constants wrap around like the uint64 arithmetic of the reference
+	p1, p2 := prime1, prime2
+	h.v = [4]uint64{p1 + p2, p2, 0, -p1}
+	h.total = 0
+	h.n = 0
+}
+
+func (h *xxHash64) Size() int      { return 8 }
+func (h *xxHash64) BlockSize() int { return 32 }
+
+func (h *xxHash64) Write(p []byte) (int, error) {
+	written := len(p)
+	h.total += uint64(written)
+
+	if h.n > 0 {
+		filled := copy(h.buf[h.n:], p)
+		h.n += filled
+		p = p[filled:]
+		if h.n < 32 {
+			return written, nil
+		}
+		h.stripe(h.buf[:])
+		h.n = 0
+	}
+
+	for len(p) >= 32 {
+		h.stripe(p[:32])
+		p = p[32:]
+	}
+	h.n = copy(h.buf[:], p)
+	return written, nil
+}
+
+func (h *xxHash64) stripe(p []byte) {
+	for i := range h.v {
+		h.v[i] = round(h.v[i], binary.LittleEndian.Uint64(p[i*8:]))
+	}
+}
+
+func (h *xxHash64) Sum64() uint64 {
+	var acc uint64
+	if h.total >= 32 {
+		acc = bits.RotateLeft64(h.v[0], 1) + bits.RotateLeft64(h.v[1], 7) +
+			bits.RotateLeft64(h.v[2], 12) + bits.RotateLeft64(h.v[3], 18)
+		for _, v := range h.v {
+			acc = mergeRound(acc, v)
+		}
+	} else {
+		acc = h.v[2] + prime5
+	}
+	acc += h.total
+
+	p := h.buf[:h.n]
+	for ; len(p) >= 8; p = p[8:] {
+		acc ^= round(0, binary.LittleEndian.Uint64(p))
+		acc = bits.RotateLeft64(acc, 27)*prime1 + prime4
+	}
+	if len(p) >= 4 {
+		acc ^= uint64(binary.LittleEndian.Uint32(p)) * prime1
+		acc = bits.RotateLeft64(acc, 23)*prime2 + prime3
+		p = p[4:]
+	}
+	for _, b := range p {
+		acc ^= uint64(b) * prime5
+		acc = bits.RotateLeft64(acc, 11) * prime1
+	}
+
+	acc ^= acc >> 33
+	acc *= prime2
+	acc ^= acc >> 29
+	acc *= prime3
+	acc ^= acc >> 32
+	return acc
+}
+
+func (h *xxHash64) Sum(b []byte) []byte {
+	return binary.BigEndian.AppendUint64(b, h.Sum64())
+}
+
+func round(acc, input uint64) uint64 {
+	acc += input * prime2
+	acc = bits.RotateLeft64(acc, 31)
+	return acc * prime1
+}
+
+func mergeRound(acc, v uint64) uint64 {
+	acc ^= round(0, v)
+	return acc*prime1 + prime4
+}
@@ -0,0 +1,32 @@
+package fileinfo
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// FS_IOC_GETFLAGS, _IOR('f', 1, long)
+const fsIocGetflags = 0x80086601
+
+/*Returns the inode flags of an entry as chattr sets them, read with the
+FS_IOC_GETFLAGS ioctl. Only regular files and directories are opened, for
+everything else and on filesystems without the ioctl an error is returned*/
+func InodeFlags(file FileInfo) (uint32, error) {
+	if !file.Mode.IsRegular() && !file.Mode.IsDir() {
+		return 0, syscall.ENOTSUP
+	}
+
+	f, err := os.OpenFile(file.Path, os.O_RDONLY|syscall.O_NONBLOCK|syscall.O_NOFOLLOW, 0)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	var flags uint64
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), fsIocGetflags, uintptr(unsafe.Pointer(&flags)))
+	if errno != 0 {
+		return 0, errno
+	}
+	return uint32(flags), nil
+}
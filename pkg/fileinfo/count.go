@@ -0,0 +1,56 @@
+package fileinfo
+
+import (
+	"os"
+	"strings"
+)
+
+type countKey struct {
+	path      string
+	hidden    bool
+	recursive bool
+}
+
+// Children counts already read during this run.
+var childCounts = map[countKey]int{}
+
+/*Returns how many entries the directory holds, its direct children or, when
+recursive is set, everything below it. Names starting with a period are only
+counted when hidden is set. The directory is read the first time it is asked
+about, later calls are answered from a cache*/
+func (f FileInfo) ChildCount(hidden, recursive bool) (int, error) {
+	key := countKey{f.Path, hidden, recursive}
+	if count, ok := childCounts[key]; ok {
+		return count, nil
+	}
+
+	count, err := countEntries(f.Path, hidden, recursive)
+	if err != nil {
+		return 0, err
+	}
+	childCounts[key] = count
+	return count, nil
+}
+
+// Directory entry types are enough here, nothing below the top is stat'ed.
+func countEntries(path string, hidden, recursive bool) (int, error) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, entry := range entries {
+		if !hidden && strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		count++
+		if recursive && entry.IsDir() {
+			below, err := countEntries(path+"/"+entry.Name(), hidden, recursive)
+			if err == nil {
+				count += below
+			}
+		}
+	}
+	return count, nil
+}
@@ -3,24 +3,39 @@ package fileinfo
 import (
 	"fmt"
 	"os"
+	"strings"
 	"syscall"
 	"time"
 )
 
 type FileInfo struct {
-	Name       string
-	Size       int64
-	Mode       os.FileMode
-	ModTime    time.Time
-	IsDir      bool
-	Nlink      uint64
-	Total      int64
-	Uid        uint32
-	Gid        uint32
-	IsLink     bool
-	LinkTarget string
-	Rdev       uint64
-	Blocks     int64
+	Name         string
+	Size         int64
+	Mode         os.FileMode
+	ModTime      time.Time
+	IsDir        bool
+	Nlink        uint64
+	Total        int64
+	Uid          uint32
+	Gid          uint32
+	IsLink       bool
+	LinkTarget   string
+	Rdev         uint64
+	Blocks       int64
+	AccessTime   time.Time
+	ChangeTime   time.Time
+	Ino          uint64
+	Dev          uint64
+	LinkIsDir    bool
+	Path         string
+	Xattrs       []string
+	HasACL       bool
+	Context      string
+	IsMountPoint bool
+	LinkBroken   bool        // the symlink target can not be reached
+	LinkErr      error       // why the symlink target can not be reached
+	TargetMode   os.FileMode // mode of the symlink target
+	TargetSize   int64       // size of the symlink target
 }
 
 //This function creates a customized FileInfo structure from the standard Golang fileInfo object
@@ -34,21 +49,94 @@ func CreateFileInfo(path string, info os.FileInfo) FileInfo {
 		IsLink:  info.Mode()&os.ModeSymlink != 0,
 	}
 
+	fileInfo.Path = fmt.Sprintf("%s/%s", path, info.Name())
+
 	if fileInfo.IsLink {
-		linkTarget, err := os.Readlink(fmt.Sprintf("%s/%s", path, info.Name()))
+		linkTarget, err := os.Readlink(fileInfo.Path)
 		if err == nil {
 			fileInfo.LinkTarget = linkTarget
 		}
+		// the kernel resolves a relative target from the directory of the link
+		if target, err := os.Stat(fileInfo.Path); err == nil {
+			fileInfo.LinkIsDir = target.IsDir()
+			fileInfo.TargetMode = target.Mode()
+			fileInfo.TargetSize = target.Size()
+		} else {
+			fileInfo.LinkBroken = true
+			fileInfo.LinkErr = err
+		}
+	}
+
+	if names, err := ListXattrs(fileInfo.Path); err == nil {
+		fileInfo.Xattrs = names
+		for _, name := range names {
+			if name == "system.posix_acl_access" || name == "system.posix_acl_default" {
+				fileInfo.HasACL = true
+			}
+		}
 	}
 
 	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
 
 		fileInfo.Nlink = stat.Nlink
+		fileInfo.Ino = stat.Ino
+		fileInfo.Dev = stat.Dev
 		fileInfo.Uid = stat.Uid
 		fileInfo.Gid = stat.Gid
 		fileInfo.Rdev = stat.Rdev
 		fileInfo.Blocks = stat.Blocks / 2
+		fileInfo.AccessTime = time.Unix(stat.Atim.Unix())
+		fileInfo.ChangeTime = time.Unix(stat.Ctim.Unix())
+	}
+
+	if fileInfo.HasSecurityContext() {
+		if label, err := GetXattr(fileInfo.Path, SecurityContextXattr); err == nil {
+			fileInfo.Context = strings.TrimRight(string(label), "\x00")
+		}
 	}
 
 	return fileInfo
 }
+
+//Returns the SELinux security context of the entry, or "?" when it has none
+func (f FileInfo) SecurityContext() string {
+	if f.Context == "" {
+		return "?"
+	}
+	return f.Context
+}
+
+/*Reports whether the entry belongs with the directories when grouping.
+Symlinks to directories count only when followLinks is set*/
+func (f FileInfo) IsDirLike(followLinks bool) bool {
+	return f.IsDir || (followLinks && f.IsLink && f.LinkIsDir)
+}
+
+/*Returns the timestamp selected with --time. The modification time is used
+when no other field was chosen*/
+func (f FileInfo) Time(field string) time.Time {
+	switch field {
+	case "atime":
+		return f.AccessTime
+	case "ctime":
+		return f.ChangeTime
+	default:
+		return f.ModTime
+	}
+}
+
+/*Returns the permission bits of a Go file mode as the Unix mode would hold
+them, with the setuid, setgid and sticky bits in their octal positions*/
+func UnixMode(mode os.FileMode) uint32 {
+	bits := uint32(mode.Perm())
+	if mode&os.ModeSetuid != 0 {
+		bits |= 0o4000
+	}
+	if mode&os.ModeSetgid != 0 {
+		bits |= 0o2000
+	}
+	if mode&os.ModeSticky != 0 {
+		bits |= 0o1000
+	}
+	return bits
+}
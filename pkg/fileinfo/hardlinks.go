@@ -0,0 +1,97 @@
+package fileinfo
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+type inodeKey struct {
+	dev, ino uint64
+}
+
+var (
+	// every name found for an inode with more than one link, see IndexHardlinks
+	hardlinkNames = map[inodeKey][]string{}
+	indexedTrees  = map[string]bool{}
+	// group numbers, handed out in the order the groups are first asked about
+	hardlinkGroups = map[inodeKey]int{}
+)
+
+/*This function records the names of every file below root with more than one
+link, so HardlinkGroup can tell which listed names share an inode. Only the
+entries of root itself are looked at, or its whole tree when recursive is
+set. The walk never leaves the device of root. Files with a single link are
+skipped right after their stat. A tree is only indexed once per run*/
+func IndexHardlinks(root string, recursive bool) {
+	root = filepath.Clean(root)
+	if indexedTrees[root] {
+		return
+	}
+	indexedTrees[root] = true
+
+	rootInfo, err := os.Stat(root)
+	if err != nil {
+		return
+	}
+	rootStat, ok := rootInfo.Sys().(*syscall.Stat_t)
+	if !ok {
+		return
+	}
+
+	filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if entry.IsDir() {
+			if path == root {
+				return nil
+			}
+			if !recursive {
+				return fs.SkipDir
+			}
+			info, err := entry.Info()
+			if err != nil {
+				return fs.SkipDir
+			}
+			if stat, ok := info.Sys().(*syscall.Stat_t); !ok || stat.Dev != rootStat.Dev {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+		stat, ok := info.Sys().(*syscall.Stat_t)
+		if !ok || stat.Nlink < 2 {
+			return nil
+		}
+		key := inodeKey{stat.Dev, stat.Ino}
+		hardlinkNames[key] = append(hardlinkNames[key], path)
+		return nil
+	})
+}
+
+/*Returns the hard link group of an entry: a number shared by every name of
+the same inode, and the names of that inode found by IndexHardlinks. Entries
+with a single link and directories are in no group and get 0*/
+func (f FileInfo) HardlinkGroup() (int, []string) {
+	if f.IsDir || f.Nlink < 2 {
+		return 0, nil
+	}
+
+	key := inodeKey{f.Dev, f.Ino}
+	id, ok := hardlinkGroups[key]
+	if !ok {
+		id = len(hardlinkGroups) + 1
+		hardlinkGroups[key] = id
+	}
+
+	names := hardlinkNames[key]
+	if len(names) == 0 {
+		names = []string{filepath.Clean(f.Path)}
+	}
+	return id, names
+}
@@ -0,0 +1,62 @@
+package fileinfo
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Filesystem types by "major:minor" device, read once from mountinfo.
+var fsTypes map[string]string
+
+/*Returns the type of the filesystem (ext4, tmpfs, overlay, nfs, fuse...) a
+device number belongs to, as listed in /proc/self/mountinfo, or "?" when the
+device is not mounted anywhere this process can see*/
+func FilesystemType(dev uint64) string {
+	if fsTypes == nil {
+		fsTypes = readMountInfo("/proc/self/mountinfo")
+	}
+	if fsType, ok := fsTypes[fmt.Sprintf("%d:%d", DevMajor(dev), DevMinor(dev))]; ok {
+		return fsType
+	}
+	return "?"
+}
+
+/*Parses mountinfo lines such as
+"36 35 98:0 /mnt1 /mnt2 rw,noatime master:1 - ext3 /dev/root rw,errors=continue"
+where the device is the third field and the type follows the "-" separator*/
+func readMountInfo(path string) map[string]string {
+	types := map[string]string{}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return types
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 {
+			continue
+		}
+		for i := 6; i < len(fields)-1; i++ {
+			if fields[i] == "-" {
+				types[fields[2]] = fields[i+1]
+				break
+			}
+		}
+	}
+	return types
+}
+
+//Returns the major number of a Linux device number
+func DevMajor(dev uint64) uint64 {
+	return ((dev >> 8) & 0xfff) | ((dev >> 32) &^ 0xfff)
+}
+
+//Returns the minor number of a Linux device number
+func DevMinor(dev uint64) uint64 {
+	return (dev & 0xff) | ((dev >> 12) &^ 0xff)
+}
@@ -0,0 +1,54 @@
+package fileinfo
+
+import (
+	"fmt"
+	"os/user"
+)
+
+// Lookups are cached, a listing asks for the same few ids over and over.
+var (
+	userNames   = map[uint32]string{}
+	groupNames  = map[uint32]string{}
+	knownUsers  = map[uint32]bool{}
+	knownGroups = map[uint32]bool{}
+)
+
+//Returns the name of the user with the given uid, or the uid itself when it has no name
+func UserName(uid uint32) string {
+	if name, ok := userNames[uid]; ok {
+		return name
+	}
+	name := fmt.Sprint(uid)
+	if usr, err := user.LookupId(name); err == nil {
+		name = usr.Username
+		knownUsers[uid] = true
+	}
+	userNames[uid] = name
+	return name
+}
+
+//Returns the name of the group with the given gid, or the gid itself when it has no name
+func GroupName(gid uint32) string {
+	if name, ok := groupNames[gid]; ok {
+		return name
+	}
+	name := fmt.Sprint(gid)
+	if grp, err := user.LookupGroupId(name); err == nil {
+		name = grp.Name
+		knownGroups[gid] = true
+	}
+	groupNames[gid] = name
+	return name
+}
+
+//Reports whether a user with the given uid exists
+func UserExists(uid uint32) bool {
+	UserName(uid)
+	return knownUsers[uid]
+}
+
+//Reports whether a group with the given gid exists
+func GroupExists(gid uint32) bool {
+	GroupName(gid)
+	return knownGroups[gid]
+}
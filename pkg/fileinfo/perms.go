@@ -0,0 +1,94 @@
+package fileinfo
+
+import (
+	"os"
+	"slices"
+	"syscall"
+)
+
+// Which set of permission bits applies to the running process.
+const (
+	ClassOwner = "owner"
+	ClassGroup = "group"
+	ClassOther = "others"
+	ClassRoot  = "superuser"
+)
+
+/*Permissions is what the mode bits of an entry allow the running process to
+do, and which class of the mode granted it*/
+type Permissions struct {
+	Class   string
+	Read    bool
+	Write   bool
+	Execute bool
+}
+
+/*Evaluates the mode bits for the effective uid and groups of the process the
+way the kernel does: the owner bits apply to the owner even when the group or
+other bits are more generous, and the superuser may read and write anything
+and execute anything with at least one execute bit. ACL entries are not
+considered*/
+func PermissionsFor(file FileInfo) Permissions {
+	perm := uint32(file.Mode.Perm())
+
+	uid := uint32(os.Geteuid())
+	if uid == 0 {
+		return Permissions{
+			Class:   ClassRoot,
+			Read:    true,
+			Write:   true,
+			Execute: file.IsDir || perm&0o111 != 0,
+		}
+	}
+
+	var class string
+	var shift uint
+	switch {
+	case uid == file.Uid:
+		class, shift = ClassOwner, 6
+	case InGroup(file.Gid):
+		class, shift = ClassGroup, 3
+	default:
+		class, shift = ClassOther, 0
+	}
+
+	bits := perm >> shift
+	return Permissions{
+		Class:   class,
+		Read:    bits&4 != 0,
+		Write:   bits&2 != 0,
+		Execute: bits&1 != 0,
+	}
+}
+
+//Reports whether the process has gid as its effective or a supplementary group
+func InGroup(gid uint32) bool {
+	if uint32(os.Getegid()) == gid {
+		return true
+	}
+	groups, err := os.Getgroups()
+	if err != nil {
+		return false
+	}
+	return slices.Contains(groups, int(gid))
+}
+
+// Access modes for CanAccess, as in access(2).
+const (
+	AccessRead    = 4
+	AccessWrite   = 2
+	AccessExecute = 1
+)
+
+/*Asks the kernel whether the invoking user may access an entry. Unlike
+PermissionsFor this goes through faccessat, so ACLs, capabilities and read-only
+mounts are taken into account. Symlinks are followed*/
+func CanAccess(file FileInfo, mode uint32) bool {
+	// syscall.Access is faccessat(AT_FDCWD, path, mode, 0)
+	return syscall.Access(file.Path, mode) == nil
+}
+
+//Reports whether the entry is a directory the invoking user can not enter
+func CannotEnter(file FileInfo) bool {
+	return file.IsDirLike(true) && !CanAccess(file, AccessExecute)
+}
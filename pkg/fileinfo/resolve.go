@@ -0,0 +1,58 @@
+package fileinfo
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// maxHops is the number of symlinks the kernel follows before giving up with ELOOP.
+const maxHops = 40
+
+// A Hop is one step of a symlink chain.
+type Hop struct {
+	Target  string   // the target as stored in the previous link
+	File    FileInfo // the entry the target names, unset when Missing
+	Missing bool
+}
+
+/*Follows a symlink one link at a time and returns every hop up to the first
+entry that is not a symlink. Relative targets are resolved from the directory
+of the link holding them. loop reports a chain that comes back to a link it
+already passed, canonical is the absolute path with every symlink resolved, or
+"" when the chain is broken or loops*/
+func ResolveChain(file FileInfo) (hops []Hop, canonical string, loop bool) {
+	visited := map[[2]uint64]bool{{file.Dev, file.Ino}: true}
+	current, target := file.Path, file.LinkTarget
+
+	for {
+		next := target
+		if !filepath.IsAbs(next) {
+			next = filepath.Dir(current) + "/" + target
+		}
+
+		info, err := os.Lstat(next)
+		if err != nil {
+			return append(hops, Hop{Target: target, Missing: true}), "", false
+		}
+		hop := CreateFileInfo(filepath.Dir(next), info)
+		hops = append(hops, Hop{Target: target, File: hop})
+
+		if !hop.IsLink {
+			break
+		}
+		if visited[[2]uint64{hop.Dev, hop.Ino}] || len(hops) >= maxHops {
+			return hops, "", true
+		}
+		visited[[2]uint64{hop.Dev, hop.Ino}] = true
+		current, target = next, hop.LinkTarget
+	}
+
+	canonical, err := filepath.EvalSymlinks(file.Path)
+	if err != nil {
+		return hops, "", false
+	}
+	if abs, err := filepath.Abs(canonical); err == nil {
+		canonical = abs
+	}
+	return hops, canonical, false
+}
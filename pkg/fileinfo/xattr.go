@@ -0,0 +1,98 @@
+package fileinfo
+
+import (
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+// SecurityContextXattr holds the SELinux label of a file.
+const SecurityContextXattr = "security.selinux"
+
+/*Returns the names of the extended attributes of path. Symlinks are not
+followed, the attributes listed are those of the link itself*/
+func ListXattrs(path string) ([]string, error) {
+	size, err := llistxattr(path, nil)
+	if err != nil || size == 0 {
+		return nil, err
+	}
+
+	buf := make([]byte, size)
+	size, err = llistxattr(path, buf)
+	if err != nil {
+		return nil, err
+	}
+
+	// the names come back NUL terminated, one after the other
+	return strings.Split(strings.TrimRight(string(buf[:size]), "\x00"), "\x00"), nil
+}
+
+//Returns the value of one extended attribute of path without following symlinks
+func GetXattr(path, name string) ([]byte, error) {
+	size, err := lgetxattr(path, name, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, size)
+	size, err = lgetxattr(path, name, buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf[:size], nil
+}
+
+//Returns the size in bytes of the value of one extended attribute of path
+func XattrSize(path, name string) (int, error) {
+	return lgetxattr(path, name, nil)
+}
+
+/*Reports whether the entry carries a security context, which ls marks with
+a '.' after the mode string*/
+func (f FileInfo) HasSecurityContext() bool {
+	for _, name := range f.Xattrs {
+		if name == SecurityContextXattr {
+			return true
+		}
+	}
+	return false
+}
+
+// The syscall package only wraps the variants that follow symlinks.
+func llistxattr(path string, dest []byte) (int, error) {
+	p, err := syscall.BytePtrFromString(path)
+	if err != nil {
+		return 0, err
+	}
+	var buf unsafe.Pointer
+	if len(dest) > 0 {
+		buf = unsafe.Pointer(&dest[0])
+	}
+	size, _, errno := syscall.Syscall(syscall.SYS_LLISTXATTR,
+		uintptr(unsafe.Pointer(p)), uintptr(buf), uintptr(len(dest)))
+	if errno != 0 {
+		return 0, errno
+	}
+	return int(size), nil
+}
+
+func lgetxattr(path, name string, dest []byte) (int, error) {
+	p, err := syscall.BytePtrFromString(path)
+	if err != nil {
+		return 0, err
+	}
+	n, err := syscall.BytePtrFromString(name)
+	if err != nil {
+		return 0, err
+	}
+	var buf unsafe.Pointer
+	if len(dest) > 0 {
+		buf = unsafe.Pointer(&dest[0])
+	}
+	size, _, errno := syscall.Syscall6(syscall.SYS_LGETXATTR,
+		uintptr(unsafe.Pointer(p)), uintptr(unsafe.Pointer(n)), uintptr(buf), uintptr(len(dest)), 0, 0)
+	if errno != 0 {
+		return 0, errno
+	}
+	return int(size), nil
+}
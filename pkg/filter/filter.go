@@ -0,0 +1,70 @@
+package filter
+
+import (
+	FI "my-ls-1/pkg/fileinfo"
+)
+
+/*A Filter decides whether an entry is part of a listing. Filters are built
+from the predicates in this package and combined with And, Or and Not*/
+type Filter interface {
+	Match(file FI.FileInfo) bool
+}
+
+// Func turns a plain function into a Filter.
+type Func func(file FI.FileInfo) bool
+
+func (f Func) Match(file FI.FileInfo) bool {
+	return f(file)
+}
+
+//Returns a filter matching the entries every one of filters matches
+func And(filters ...Filter) Filter {
+	if len(filters) == 1 {
+		return filters[0]
+	}
+	return Func(func(file FI.FileInfo) bool {
+		for _, f := range filters {
+			if !f.Match(file) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+//Returns a filter matching the entries at least one of filters matches
+func Or(filters ...Filter) Filter {
+	if len(filters) == 1 {
+		return filters[0]
+	}
+	return Func(func(file FI.FileInfo) bool {
+		for _, f := range filters {
+			if f.Match(file) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+//Returns a filter matching the entries f does not match
+func Not(f Filter) Filter {
+	return Func(func(file FI.FileInfo) bool {
+		return !f.Match(file)
+	})
+}
+
+/*Returns the entries of files that f matches, in their original order. A nil
+filter keeps everything*/
+func Apply(files []FI.FileInfo, f Filter) []FI.FileInfo {
+	if f == nil {
+		return files
+	}
+	kept := files[:0:0]
+	for _, file := range files {
+		if f.Match(file) {
+			kept = append(kept, file)
+		}
+	}
+	return kept
+}
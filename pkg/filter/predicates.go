@@ -0,0 +1,275 @@
+package filter
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	FI "my-ls-1/pkg/fileinfo"
+)
+
+/*Matches entries of the given types, a comma separated list of the find
+letters: f regular file, d directory, l symlink, p pipe, s socket, b block
+device and c character device*/
+func Type(types string) (Filter, error) {
+	wanted := map[string]bool{}
+	for _, t := range strings.Split(types, ",") {
+		if !strings.Contains("fdlpsbc", t) || len(t) != 1 {
+			return nil, fmt.Errorf("unknown type '%s'", t)
+		}
+		wanted[t] = true
+	}
+	return Func(func(file FI.FileInfo) bool {
+		return wanted[typeLetter(file)]
+	}), nil
+}
+
+func typeLetter(file FI.FileInfo) string {
+	switch {
+	case file.IsDir:
+		return "d"
+	case file.IsLink:
+		return "l"
+	case file.Mode&os.ModeNamedPipe != 0:
+		return "p"
+	case file.Mode&os.ModeSocket != 0:
+		return "s"
+	case file.Mode&os.ModeCharDevice != 0:
+		return "c"
+	case file.Mode&os.ModeDevice != 0:
+		return "b"
+	}
+	return "f"
+}
+
+/*Matches on the size in bytes: "+10M" is more than 10 MiB, "-1k" less than
+1 KiB and "512" exactly 512 bytes. The units are k, M, G and T, powers of 1024*/
+func Size(spec string) (Filter, error) {
+	sign, value := splitSign(spec)
+	size, err := ParseSize(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid size '%s'", spec)
+	}
+	return Func(func(file FI.FileInfo) bool {
+		return compareSigned(sign, file.Size, size)
+	}), nil
+}
+
+//Parses a size such as "512", "10k" or "2G" into bytes, the units are powers of 1024
+func ParseSize(value string) (int64, error) {
+	return parseScaled(value, map[byte]int64{
+		'c': 1, 'k': 1 << 10, 'K': 1 << 10, 'M': 1 << 20, 'G': 1 << 30, 'T': 1 << 40,
+	})
+}
+
+/*Matches on the age of the modification time: "-7d" is modified less than
+seven days ago, "+2h" more than two hours ago. The units are s, m, h, d and w*/
+func ModifiedWithin(spec string) (Filter, error) {
+	sign, value := splitSign(spec)
+	age, err := parseScaled(value, map[byte]int64{
+		's': int64(time.Second), 'm': int64(time.Minute), 'h': int64(time.Hour),
+		'd': int64(24 * time.Hour), 'w': int64(7 * 24 * time.Hour),
+	})
+	if err != nil || sign == 0 {
+		return nil, fmt.Errorf("invalid age '%s'", spec)
+	}
+	now := time.Now()
+	return Func(func(file FI.FileInfo) bool {
+		return compareSigned(sign, int64(now.Sub(file.ModTime)), age)
+	}), nil
+}
+
+//Matches entries modified after the file at path was
+func NewerThan(path string) (Filter, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	reference := info.ModTime()
+	return Func(func(file FI.FileInfo) bool {
+		return file.ModTime.After(reference)
+	}), nil
+}
+
+//Matches entries owned by a user, given by name or numeric uid
+func User(name string) (Filter, error) {
+	uid, err := strconv.ParseUint(name, 10, 32)
+	if err != nil {
+		usr, lookupErr := user.Lookup(name)
+		if lookupErr != nil {
+			return nil, lookupErr
+		}
+		uid, _ = strconv.ParseUint(usr.Uid, 10, 32)
+	}
+	return Func(func(file FI.FileInfo) bool {
+		return file.Uid == uint32(uid)
+	}), nil
+}
+
+/*Matches on the permission bits the way find -perm does: "MODE" needs exactly
+these bits, "-MODE" at least all of them and "/MODE" any of them. MODE is
+octal (644) or symbolic (u+x,g+w)*/
+func Perm(spec string) (Filter, error) {
+	kind := byte(0)
+	if spec != "" && (spec[0] == '-' || spec[0] == '/') {
+		kind, spec = spec[0], spec[1:]
+	}
+	mode, err := ParseMode(spec)
+	if err != nil {
+		return nil, err
+	}
+	return Func(func(file FI.FileInfo) bool {
+		bits := FI.UnixMode(file.Mode) & 0o7777
+		switch kind {
+		case '-':
+			return bits&mode == mode
+		case '/':
+			return mode == 0 || bits&mode != 0
+		}
+		return bits == mode
+	}), nil
+}
+
+//Matches empty regular files and directories without entries
+func Empty() Filter {
+	return Func(func(file FI.FileInfo) bool {
+		if file.IsDir {
+			entries, err := os.ReadDir(file.Path)
+			return err == nil && len(entries) == 0
+		}
+		return file.Mode.IsRegular() && file.Size == 0
+	})
+}
+
+//Matches symlinks whose target does not exist
+func BrokenLink() Filter {
+	return Func(func(file FI.FileInfo) bool {
+		return file.IsLink && file.LinkBroken
+	})
+}
+
+//Matches names against a shell glob such as "*.log"
+func Name(glob string) (Filter, error) {
+	if _, err := path.Match(glob, ""); err != nil {
+		return nil, err
+	}
+	return Func(func(file FI.FileInfo) bool {
+		matched, _ := path.Match(glob, file.Name)
+		return matched
+	}), nil
+}
+
+//Matches names against a regular expression, which has to match the whole name
+func Regex(expr string) (Filter, error) {
+	re, err := regexp.Compile("^(?:" + expr + ")$")
+	if err != nil {
+		return nil, err
+	}
+	return Func(func(file FI.FileInfo) bool {
+		return re.MatchString(file.Name)
+	}), nil
+}
+
+/*Parses a chmod style mode, either octal ("4755") or symbolic clauses such as
+"u+x,go=r" applied to an empty mode. Returns the Unix permission bits*/
+func ParseMode(spec string) (uint32, error) {
+	if octal, err := strconv.ParseUint(spec, 8, 32); err == nil && octal <= 0o7777 {
+		return uint32(octal), nil
+	}
+
+	var mode uint32
+	for _, clause := range strings.Split(spec, ",") {
+		i := strings.IndexAny(clause, "+-=")
+		if i == -1 {
+			return 0, fmt.Errorf("invalid mode '%s'", spec)
+		}
+		who, op, perms := clause[:i], clause[i], clause[i+1:]
+		if who == "" {
+			who = "a"
+		}
+
+		var whoMask uint32
+		for _, w := range who {
+			switch w {
+			case 'u':
+				whoMask |= 0o4700
+			case 'g':
+				whoMask |= 0o2070
+			case 'o':
+				whoMask |= 0o1007
+			case 'a':
+				whoMask |= 0o7777
+			default:
+				return 0, fmt.Errorf("invalid mode '%s'", spec)
+			}
+		}
+
+		var bits uint32
+		for _, p := range perms {
+			switch p {
+			case 'r':
+				bits |= 0o444
+			case 'w':
+				bits |= 0o222
+			case 'x':
+				bits |= 0o111
+			case 's':
+				bits |= 0o6000
+			case 't':
+				bits |= 0o1000
+			default:
+				return 0, fmt.Errorf("invalid mode '%s'", spec)
+			}
+		}
+		bits &= whoMask
+
+		switch op {
+		case '+':
+			mode |= bits
+		case '-':
+			mode &^= bits
+		case '=':
+			mode = mode&^(whoMask&0o777) | bits
+		}
+	}
+	return mode, nil
+}
+
+// Splits a leading '+' or '-' off a value.
+func splitSign(spec string) (byte, string) {
+	if spec != "" && (spec[0] == '+' || spec[0] == '-') {
+		return spec[0], spec[1:]
+	}
+	return 0, spec
+}
+
+// Parses a number followed by an optional one letter unit.
+func parseScaled(value string, units map[byte]int64) (int64, error) {
+	scale := int64(1)
+	if value != "" {
+		if unit, ok := units[value[len(value)-1]]; ok {
+			scale = unit
+			value = value[:len(value)-1]
+		}
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid number '%s'", value)
+	}
+	return n * scale, nil
+}
+
+func compareSigned(sign byte, value, limit int64) bool {
+	switch sign {
+	case '+':
+		return value > limit
+	case '-':
+		return value < limit
+	}
+	return value == limit
+}
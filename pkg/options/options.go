@@ -3,7 +3,11 @@ package options
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
+
+	H "my-ls-1/pkg/checksum"
+	F "my-ls-1/pkg/filter"
 )
 
 type Options struct {
@@ -15,6 +19,133 @@ type Options struct {
 	SortBySize bool // -S
 	OnePerLine bool // -1
 	NoColor    bool
+	TimeStyle  string    // --time-style
+	TimeField  string    // --time
+	SortKeys   []SortKey // --sort, -X, -v, -U
+	GroupDirs  string    // --group-directories-first ("first"), --dirs-last ("last")
+	LinksAsDir bool      // --links-as-dirs
+	Quoting    string    // --quoting-style, -Q, -b, -N
+	HideCtrl   bool      // -q
+	Across     bool      // -x
+	Commas     bool      // -m
+	TabSize    int       // -T, --tabsize
+	Xattrs     bool      // -@, --xattrs
+	Context    bool      // -Z, --context
+	Attrs      bool      // --attrs
+	FsType     bool      // --fs
+	DiskUsage  string    // --du ("apparent"), --du-blocks ("blocks")
+	OneFS      bool      // --one-file-system
+	Count      bool      // --count
+	CountAll   bool      // --count=all
+	CountDeep  bool      // --count=recursive
+	Summary    bool      // --summary
+	Filter     F.Filter  // --type, --size, --name... combined with --not and --or
+	Columns    []string  // --columns
+	NoOwner    bool      // -g
+	NoGroup    bool      // -o, -G
+	Header     bool      // --header
+	TableStyle string    // --table-style
+	Octal      string    // --octal ("beside"), --octal=only ("only")
+	Explain    bool      // --explain-perms
+	Access     bool      // --access
+	Audit      bool      // --audit
+	LinkInfo   bool      // --link-info
+	Resolve    bool      // --resolve
+	Hardlinks  string    // --hardlinks ("groups"), --hardlinks=names ("names")
+	Checksum   string    // --checksum
+	MaxHashed  int64     // --checksum-max-size, 0 for no limit
+	HashCache  string    // --checksum-cache
+
+	filters filterBuilder
+}
+
+/*Collects the filter flags while parsing. Consecutive filters are ANDed,
+--or starts a new group and --not negates the filter that follows it*/
+type filterBuilder struct {
+	groups [][]F.Filter
+	group  []F.Filter
+	negate bool
+}
+
+func (b *filterBuilder) add(f F.Filter) {
+	if b.negate {
+		f = F.Not(f)
+		b.negate = false
+	}
+	b.group = append(b.group, f)
+}
+
+func (b *filterBuilder) or() {
+	if len(b.group) > 0 {
+		b.groups = append(b.groups, b.group)
+		b.group = nil
+	}
+}
+
+// Returns the combined filter, or nil when no filter flag was given.
+func (b *filterBuilder) build() F.Filter {
+	b.or()
+	if len(b.groups) == 0 {
+		return nil
+	}
+	var alternatives []F.Filter
+	for _, group := range b.groups {
+		alternatives = append(alternatives, F.And(group...))
+	}
+	return F.Or(alternatives...)
+}
+
+// SortKey is one link of a --sort chain. Reverse flips only this key.
+type SortKey struct {
+	Name    string
+	Reverse bool
+}
+
+// sortKeyAliases maps every accepted --sort word to its canonical key name.
+var sortKeyAliases = map[string]string{
+	"none":      "none",
+	"name":      "name",
+	"size":      "size",
+	"time":      "time",
+	"extension": "extension",
+	"ext":       "extension",
+	"version":   "version",
+	"width":     "width",
+	"inode":     "inode",
+	"owner":     "owner",
+	"group":     "group",
+	"type":      "type",
+}
+
+/*The long options whose value is required. Like getopt, they accept it as
+--name=value or as the argument after --name*/
+var requiresValue = map[string]bool{
+	"time-style":        true,
+	"sort":              true,
+	"type":              true,
+	"size":              true,
+	"newer":             true,
+	"mtime":             true,
+	"user":              true,
+	"perm":              true,
+	"name":              true,
+	"regex":             true,
+	"checksum-max-size": true,
+	"checksum-cache":    true,
+	"table-style":       true,
+	"columns":           true,
+	"tabsize":           true,
+	"quoting-style":     true,
+	"time":              true,
+}
+
+var quotingStyles = map[string]bool{
+	"literal":      true,
+	"shell":        true,
+	"shell-always": true,
+	"shell-escape": true,
+	"c":            true,
+	"escape":       true,
 }
 
 /*The function will collect the command line arguments and sort them into flags
@@ -24,14 +155,31 @@ as well as the array containing the files/directories to be displayed by the ls
 func ParseFlags() (Options, []string) {
 	var options Options
 
+	// like GNU ls, nonprintable characters never reach a terminal raw unless
+	// --show-control-chars asks for them
+	options.HideCtrl = stdoutIsTerminal()
+
 	args := os.Args[1:]
 
 	var dirs []string
 
+nextArg:
 	for i := 0; i < len(args); i++ {
 		arg := args[i]
-		if strings.HasPrefix(arg, "-") && len(arg) > 1 && arg != "--" {
-			for _, flag := range arg[1:] {
+		if strings.HasPrefix(arg, "--") && len(arg) > 2 {
+			flag := arg[2:]
+			// an option that needs a value takes the next argument when it has no '='
+			if requiresValue[flag] {
+				if i+1 == len(args) {
+					fmt.Printf("ls: option '--%s' requires an argument\n", flag)
+					os.Exit(1)
+				}
+				i++
+				flag += "=" + args[i]
+			}
+			parseLongFlag(flag, &options)
+		} else if strings.HasPrefix(arg, "-") && len(arg) > 1 && arg != "--" {
+			for k, flag := range arg[1:] {
 				switch flag {
 				case 'l':
 					options.LongFormat = true
@@ -45,10 +193,49 @@ func ParseFlags() (Options, []string) {
 					options.SortByTime = true
 				case 'S':
 					options.SortBySize = true
+				case 'X':
+					options.SortKeys = []SortKey{{Name: "extension"}}
+				case 'v':
+					options.SortKeys = []SortKey{{Name: "version"}}
+				case 'U':
+					options.SortKeys = []SortKey{{Name: "none"}}
+				case 'Q':
+					options.Quoting = "c"
+				case 'b':
+					options.Quoting = "escape"
+				case 'N':
+					options.Quoting = "literal"
+				case 'q':
+					options.HideCtrl = true
+				case 'x':
+					options.Across = true
+				case 'C':
+					options.Across = false
+				case 'm':
+					options.Commas = true
+				case 'T':
+					// the tab size is the rest of the argument or the next one
+					value := arg[2+k:]
+					if value == "" && i+1 < len(args) {
+						i++
+						value = args[i]
+					}
+					options.TabSize = parseTabSize(value)
+					continue nextArg
+				case 'Z':
+					options.Context = true
+				case '@':
+					options.Xattrs = true
 				case '1':
 					options.OnePerLine = true
+				case 'g':
+					options.LongFormat = true
+					options.NoOwner = true
+				case 'o':
+					options.LongFormat = true
+					options.NoGroup = true
 				case 'G':
-					options.NoColor = true
+					options.NoGroup = true
 				default:
 					fmt.Printf("ls: invalid option -- '%c'\n", flag)
 					os.Exit(1)
@@ -62,5 +249,270 @@ func ParseFlags() (Options, []string) {
 		}
 	}
 
+	if options.filters.negate {
+		danglingNot()
+	}
+	options.Filter = options.filters.build()
+
+	if options.Quoting == "" {
+		options.Quoting = defaultQuoting()
+	}
+
 	return options, dirs
 }
+
+/*Returns the quoting style used when none was given: $QUOTING_STYLE when it
+names a valid style, shell-escape on a terminal and literal otherwise*/
+func defaultQuoting() string {
+	if style := os.Getenv("QUOTING_STYLE"); quotingStyles[style] {
+		return style
+	}
+	if stdoutIsTerminal() {
+		return "shell-escape"
+	}
+	return "literal"
+}
+
+func stdoutIsTerminal() bool {
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+/*Handles the options spelled out in full (--name or --name=value). Unknown
+options and invalid values terminate the program the same way invalid short
+flags do*/
+func parseLongFlag(arg string, options *Options) {
+	name, value, _ := strings.Cut(arg, "=")
+
+	switch name {
+	case "time-style":
+		switch value {
+		case "relative", "full-iso", "long-iso", "iso", "locale":
+			options.TimeStyle = value
+		default:
+			invalidArgument(value, name)
+		}
+	case "sort":
+		keys, ok := ParseSortKeys(value)
+		if !ok {
+			invalidArgument(value, name)
+		}
+		options.SortKeys = keys
+	case "du":
+		options.DiskUsage = "apparent"
+	case "du-blocks":
+		options.DiskUsage = "blocks"
+	case "one-file-system":
+		options.OneFS = true
+	case "type", "size", "newer", "mtime", "user", "perm", "name", "regex":
+		f, err := newFilter(name, value)
+		if err != nil {
+			invalidArgument(value, name)
+		}
+		options.filters.add(f)
+	case "empty":
+		options.filters.add(F.Empty())
+	case "broken-links":
+		options.filters.add(F.BrokenLink())
+	case "not":
+		options.filters.negate = !options.filters.negate
+	case "or":
+		if options.filters.negate {
+			danglingNot()
+		}
+		options.filters.or()
+	case "octal":
+		switch value {
+		case "", "beside":
+			options.Octal = "beside"
+		case "only":
+			options.Octal = "only"
+		default:
+			invalidArgument(value, name)
+		}
+	case "checksum":
+		if value == "" {
+			value = "sha256"
+		}
+		if !H.Supported(value) {
+			invalidArgument(value, name)
+		}
+		options.Checksum = value
+	case "checksum-max-size":
+		size, err := F.ParseSize(value)
+		if err != nil {
+			invalidArgument(value, name)
+		}
+		options.MaxHashed = size
+	case "checksum-cache":
+		if value == "" {
+			invalidArgument(value, name)
+		}
+		options.HashCache = value
+	case "hardlinks":
+		switch value {
+		case "", "groups":
+			options.Hardlinks = "groups"
+		case "names":
+			options.Hardlinks = "names"
+		default:
+			invalidArgument(value, name)
+		}
+	case "resolve":
+		options.Resolve = true
+	case "link-info":
+		options.LinkInfo = true
+	case "audit":
+		options.Audit = true
+	case "access":
+		options.Access = true
+	case "explain-perms":
+		options.Explain = true
+		options.LongFormat = true
+	case "header":
+		options.Header = true
+	case "table-style":
+		switch value {
+		case "plain", "grid", "markdown", "csv", "tsv":
+			options.TableStyle = value
+		default:
+			invalidArgument(value, name)
+		}
+	case "columns":
+		options.Columns = strings.Split(value, ",")
+	case "no-group":
+		options.NoGroup = true
+	case "color", "colour":
+		switch value {
+		case "never", "no", "none":
+			options.NoColor = true
+		case "", "always", "yes", "force", "auto", "tty", "if-tty":
+			options.NoColor = false
+		default:
+			invalidArgument(value, name)
+		}
+	case "summary":
+		options.Summary = true
+	case "count":
+		options.Count = true
+		for _, word := range strings.Split(value, ",") {
+			switch word {
+			case "":
+			case "all":
+				options.CountAll = true
+			case "recursive":
+				options.CountDeep = true
+			default:
+				invalidArgument(value, name)
+			}
+		}
+	case "fs":
+		options.FsType = true
+	case "attrs":
+		options.Attrs = true
+	case "context":
+		options.Context = true
+	case "xattrs":
+		options.Xattrs = true
+	case "tabsize":
+		options.TabSize = parseTabSize(value)
+	case "quoting-style":
+		if !quotingStyles[value] {
+			invalidArgument(value, name)
+		}
+		options.Quoting = value
+	case "quote-name":
+		options.Quoting = "c"
+	case "escape":
+		options.Quoting = "escape"
+	case "literal":
+		options.Quoting = "literal"
+	case "hide-control-chars":
+		options.HideCtrl = true
+	case "show-control-chars":
+		options.HideCtrl = false
+	case "group-directories-first":
+		options.GroupDirs = "first"
+	case "dirs-last":
+		options.GroupDirs = "last"
+	case "links-as-dirs":
+		options.LinksAsDir = true
+	case "time":
+		switch value {
+		case "mtime", "modification":
+			options.TimeField = "mtime"
+		case "atime", "access", "use":
+			options.TimeField = "atime"
+		case "ctime", "status":
+			options.TimeField = "ctime"
+		default:
+			invalidArgument(value, name)
+		}
+	default:
+		fmt.Printf("ls: unrecognized option '--%s'\n", arg)
+		os.Exit(1)
+	}
+}
+
+//Builds the filter for one of the predicate flags
+func newFilter(name, value string) (F.Filter, error) {
+	switch name {
+	case "type":
+		return F.Type(value)
+	case "size":
+		return F.Size(value)
+	case "newer":
+		return F.NewerThan(value)
+	case "mtime":
+		return F.ModifiedWithin(value)
+	case "user":
+		return F.User(value)
+	case "perm":
+		return F.Perm(value)
+	case "name":
+		return F.Name(value)
+	default:
+		return F.Regex(value)
+	}
+}
+
+//Parses the value of -T and --tabsize, a tab size of 0 means spaces only
+func parseTabSize(value string) int {
+	size, err := strconv.Atoi(value)
+	if err != nil || size < 0 {
+		invalidArgument(value, "tabsize")
+	}
+	return size
+}
+
+/*Splits a --sort value such as "ext,-size" into its keys. A leading '-'
+reverses that key only. Returns false when a word is not a known key*/
+func ParseSortKeys(value string) ([]SortKey, bool) {
+	var keys []SortKey
+	for _, word := range strings.Split(value, ",") {
+		key := SortKey{}
+		if strings.HasPrefix(word, "-") {
+			key.Reverse = true
+			word = word[1:]
+		}
+		canonical, ok := sortKeyAliases[word]
+		if !ok {
+			return nil, false
+		}
+		key.Name = canonical
+		keys = append(keys, key)
+	}
+	return keys, true
+}
+
+//Reports a --not that no filter follows and exits
+func danglingNot() {
+	fmt.Println("ls: option '--not' must be followed by a filter")
+	os.Exit(1)
+}
+
+//Reports an invalid value given to a long option and exits
+func invalidArgument(value, name string) {
+	fmt.Printf("ls: invalid argument '%s' for '--%s'\n", value, name)
+	os.Exit(1)
+}
@@ -14,6 +14,14 @@ const (
 
 var colorMap map[string]string
 
+/*Colors of the keys this ls adds to LS_COLORS, used when LS_COLORS is set but
+leaves them out: bold red for directories the user can not enter and
+underlined bold blue for mount points*/
+var defaultColors = map[string]string{
+	"nx": "01;31",
+	"mp": "01;34;4",
+}
+
 /*This function will initialize the color environment variable and
 declare process it filling the map we declared to hold the colors
 of the different files*/
@@ -27,18 +35,42 @@ func InitColorMap() {
 	pairs := strings.Split(lsColors, ":")
 	for _, pair := range pairs {
 		parts := strings.Split(pair, "=")
-		if len(parts) == 2 {
+		if len(parts) == 2 && parts[1] == "" {
+			// an empty code turns the color of that key off
+			colorMap[parts[0]] = ""
+		} else if len(parts) == 2 {
 			colorMap[parts[0]] = "\033[" + parts[1] + "m"
 		}
 	}
+
+	for key, code := range defaultColors {
+		if _, ok := colorMap[key]; !ok {
+			colorMap[key] = "\033[" + code + "m"
+		}
+	}
 }
 
-/*Declares the color of the files based on their types of files they are and extensions*/
-func Colorize(file FI.FileInfo, name string) string {
+/*Wraps text in the color of the file. The color is looked up from the raw
+name, so quoting the text first does not hide its extension*/
+func Colorize(file FI.FileInfo, name, text string) string {
+	return Wrap(ColorFor(file, name), text)
+}
+
+/*Declares the color of the files based on their types of files they are and extensions.
+Directories the user can not enter take the "nx" color, mount points the "mp"
+color and broken symlinks the "or" color when LS_COLORS sets it. name is the
+unquoted name or path*/
+func ColorFor(file FI.FileInfo, name string) string {
 	var colorCode string
 
-	if file.IsDir {
+	if colorMap["nx"] != "" && FI.CannotEnter(file) {
+		colorCode = colorMap["nx"]
+	} else if file.IsMountPoint && colorMap["mp"] != "" {
+		colorCode = colorMap["mp"]
+	} else if file.IsDir {
 		colorCode = colorMap["di"]
+	} else if file.IsLink && file.LinkBroken && colorMap["or"] != "" {
+		colorCode = colorMap["or"]
 	} else if file.IsLink {
 		colorCode = colorMap["ln"]
 	} else if file.Mode&0o111 != 0 {
@@ -56,11 +88,20 @@ func Colorize(file FI.FileInfo, name string) string {
 		}
 	}
 
-	if colorCode == "" {
-		return name
+	return colorCode
+}
+
+//Surrounds text with a color code and the reset sequence, text alone when there is no code
+func Wrap(code, text string) string {
+	if code == "" {
+		return text
 	}
+	return code + text + ColorReset
+}
 
-	return colorCode + name + ColorReset
+//Colors the target of a broken symlink with the "mi" color
+func Missing(name string) string {
+	return Wrap(colorMap["mi"], name)
 }
 
 //Tries to process the extension of a particular file
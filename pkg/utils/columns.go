@@ -0,0 +1,73 @@
+package utils
+
+import "strings"
+
+// minColumnWidth is the narrowest a column can be: one cell and the two space gap.
+const minColumnWidth = 3
+
+/*Finds the largest number of columns whose lines stay narrower than the
+terminal, the way GNU ls does. Each column is as wide as its widest entry plus
+a two space gap, except the last column which gets no gap. Returns the number
+of columns and the width of each*/
+func fitColumns(widths []int, termWidth int, across bool) (int, []int) {
+	maxCols := termWidth / minColumnWidth
+	if maxCols > len(widths) {
+		maxCols = len(widths)
+	}
+
+	for numCols := maxCols; numCols > 1; numCols-- {
+		numRows := (len(widths) + numCols - 1) / numCols
+		if !across && (numCols-1)*numRows >= len(widths) {
+			// the last column would be empty, a smaller count gives this layout
+			continue
+		}
+
+		colWidths := make([]int, numCols)
+		for idx, width := range widths {
+			col := idx / numRows
+			if across {
+				col = idx % numCols
+			}
+			if col < numCols-1 {
+				width += 2
+			}
+			if width > colWidths[col] {
+				colWidths[col] = width
+			}
+		}
+
+		lineWidth := 0
+		for _, width := range colWidths {
+			lineWidth += width
+		}
+		if lineWidth < termWidth {
+			return numCols, colWidths
+		}
+	}
+
+	return 1, []int{0}
+}
+
+// Returns the index of the entry shown at row, col of the grid.
+func cellIndex(row, col, numRows, numCols int, across bool) int {
+	if across {
+		return row*numCols + col
+	}
+	return col*numRows + row
+}
+
+/*Returns the padding that moves the cursor from column from to column to.
+With a tab size (-T) tabs are used wherever a tab stop lies on the way*/
+func indent(from, to, tabSize int) string {
+	var b strings.Builder
+	for from < to {
+		if tabSize > 0 && to/tabSize > (from+1)/tabSize {
+			b.WriteByte('\t')
+			from += tabSize - from%tabSize
+		} else {
+			b.WriteByte(' ')
+			from++
+		}
+	}
+	return b.String()
+}
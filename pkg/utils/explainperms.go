@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	FI "my-ls-1/pkg/fileinfo"
+)
+
+/*This function spells out the permissions of an entry below its long format
+line: what the owner, the group and everyone else may do, what the running
+process may do and which special bits are set*/
+func PrintPermissionExplanation(file FI.FileInfo) {
+	for _, line := range ExplainPermissions(file) {
+		fmt.Printf("\t%s\n", line)
+	}
+}
+
+//Returns the lines PrintPermissionExplanation prints
+func ExplainPermissions(file FI.FileInfo) []string {
+	if file.IsLink {
+		return []string{"symlink: access is decided by the permissions of its target"}
+	}
+
+	perm := file.Mode.Perm()
+	lines := []string{
+		fmt.Sprintf("owner %s: %s", FI.UserName(file.Uid), describeAccess(file, perm>>6&4 != 0, perm>>6&2 != 0, perm>>6&1 != 0)),
+		fmt.Sprintf("group %s: %s", FI.GroupName(file.Gid), describeAccess(file, perm>>3&4 != 0, perm>>3&2 != 0, perm>>3&1 != 0)),
+		fmt.Sprintf("others: %s", describeAccess(file, perm&4 != 0, perm&2 != 0, perm&1 != 0)),
+	}
+
+	you := FI.PermissionsFor(file)
+	lines = append(lines, fmt.Sprintf("you (%s): %s", you.Class, describeAccess(file, you.Read, you.Write, you.Execute)))
+
+	if file.Mode&os.ModeSetuid != 0 {
+		lines = append(lines, fmt.Sprintf("setuid: runs as user %s", FI.UserName(file.Uid)))
+	}
+	if file.Mode&os.ModeSetgid != 0 {
+		if file.IsDir {
+			lines = append(lines, fmt.Sprintf("setgid: new entries belong to group %s", FI.GroupName(file.Gid)))
+		} else {
+			lines = append(lines, fmt.Sprintf("setgid: runs as group %s", FI.GroupName(file.Gid)))
+		}
+	}
+	if file.Mode&os.ModeSticky != 0 && file.IsDir {
+		lines = append(lines, "sticky: only the owner of an entry may delete or rename it")
+	}
+	if file.HasACL {
+		lines = append(lines, "acl: an access control list may grant or deny more than shown")
+	}
+	return lines
+}
+
+/*Words for the read, write and execute bits. For a directory they mean
+listing it, adding and removing entries and entering it*/
+func describeAccess(file FI.FileInfo, read, write, execute bool) string {
+	words := [3]string{"read", "write", "execute"}
+	if file.IsDir {
+		words = [3]string{"list", "create and delete entries", "enter"}
+	}
+
+	var allowed []string
+	for i, ok := range []bool{read, write, execute} {
+		if ok {
+			allowed = append(allowed, words[i])
+		}
+	}
+	if len(allowed) == 0 {
+		return "no access"
+	}
+	return strings.Join(allowed, ", ")
+}
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"strings"
+
+	FI "my-ls-1/pkg/fileinfo"
+)
+
+// inodeFlags lists the chattr flags in the order lsattr prints them.
+var inodeFlags = []struct {
+	mask   uint32
+	letter byte
+}{
+	{0x00000001, 's'}, // secure deletion
+	{0x00000002, 'u'}, // undelete
+	{0x00000008, 'S'}, // synchronous updates
+	{0x00010000, 'D'}, // synchronous directory updates
+	{0x00000010, 'i'}, // immutable
+	{0x00000020, 'a'}, // append only
+	{0x00000040, 'd'}, // no dump
+	{0x00000080, 'A'}, // no atime updates
+	{0x00000004, 'c'}, // compressed
+	{0x00000800, 'E'}, // encrypted
+	{0x00004000, 'j'}, // data journaling
+	{0x00001000, 'I'}, // indexed directory
+	{0x00008000, 't'}, // no tail merging
+	{0x00020000, 'T'}, // top of directory hierarchy
+	{0x00080000, 'e'}, // extents
+	{0x00800000, 'C'}, // no copy on write
+	{0x02000000, 'x'}, // direct access
+	{0x40000000, 'F'}, // case folded
+	{0x10000000, 'N'}, // inline data
+	{0x20000000, 'P'}, // project hierarchy
+	{0x00100000, 'V'}, // verity
+	{0x00000400, 'm'}, // don't compress
+}
+
+/*Returns the lsattr style flag string of an entry, one letter or '-' per flag.
+Entries whose flags cannot be read get a row of '?' of the same width*/
+func FormatInodeFlags(file FI.FileInfo) string {
+	flags, err := FI.InodeFlags(file)
+	if err != nil {
+		return strings.Repeat("?", len(inodeFlags))
+	}
+
+	result := make([]byte, len(inodeFlags))
+	for i, flag := range inodeFlags {
+		result[i] = '-'
+		if flags&flag.mask != 0 {
+			result[i] = flag.letter
+		}
+	}
+	return string(result)
+}
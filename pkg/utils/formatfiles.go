@@ -1,22 +1,120 @@
 package utils
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"strings"
+	"syscall"
 
 	FI "my-ls-1/pkg/fileinfo"
 	OP "my-ls-1/pkg/options"
 	C "my-ls-1/pkg/utils/color"
 )
 
-//Returns  a file name colored according to options passed
+//Returns  a file name quoted and colored according to options passed
 func FormatFileName(file FI.FileInfo, options OP.Options) string {
-	name := file.Name
-	if !options.NoColor {
-		name = C.Colorize(file, name)
-	}
+	name := ColoredName(file, options)
 	if file.IsLink {
-		name += " -> " + file.LinkTarget
+		name += " -> " + FormatLinkTarget(file, options)
+	}
+	return name
+}
+
+/*Returns the quoted target of a symlink. A missing target takes the "mi"
+color. In the long format --resolve shows the whole chain of links and
+--link-info adds what the target is*/
+func FormatLinkTarget(file FI.FileInfo, options OP.Options) string {
+	var target string
+	explained := false
+	if options.Resolve && options.LongFormat {
+		target, explained = formatChain(file, options)
+	} else {
+		target = QuoteName(file.LinkTarget, options)
+		if file.LinkBroken && !options.NoColor {
+			target = C.Missing(target)
+		}
+	}
+	// a chain that loops or breaks already says so, it is not said twice
+	if options.LinkInfo && options.LongFormat && !explained {
+		target += " " + linkInfo(file)
+	}
+	return target
+}
+
+/*Formats every hop of a symlink chain, "b -> ../c -> /real/d [/real/d]", each
+hop colored by what it is. The canonical path closes the chain in brackets,
+a chain that loops or breaks says so instead and reports it*/
+func formatChain(file FI.FileInfo, options OP.Options) (string, bool) {
+	hops, canonical, loop := FI.ResolveChain(file)
+
+	var chain strings.Builder
+	for i, hop := range hops {
+		if i > 0 {
+			chain.WriteString(" -> ")
+		}
+		name := QuoteName(hop.Target, options)
+		switch {
+		case options.NoColor:
+		case hop.Missing:
+			name = C.Missing(name)
+		default:
+			name = C.Colorize(hop.File, hop.Target, name)
+		}
+		chain.WriteString(name)
+	}
+
+	switch {
+	case loop:
+		chain.WriteString(" (symlink loop)")
+		return chain.String(), true
+	case len(hops) > 0 && hops[len(hops)-1].Missing:
+		chain.WriteString(" (broken)")
+		return chain.String(), true
+	case canonical != "":
+		chain.WriteString(" [" + QuoteName(canonical, options) + "]")
+	}
+	return chain.String(), false
+}
+
+/*Describes the target of a symlink as "(type, size, mode)", or says why it
+can not be reached*/
+func linkInfo(file FI.FileInfo) string {
+	if file.LinkBroken {
+		switch {
+		case errors.Is(file.LinkErr, syscall.ELOOP):
+			return "(symlink loop)"
+		case errors.Is(file.LinkErr, os.ErrNotExist), errors.Is(file.LinkErr, syscall.ENOTDIR):
+			return "(missing)"
+		default:
+			return "(inaccessible)"
+		}
+	}
+
+	var kind string
+	mode := file.TargetMode
+	switch {
+	case mode.IsDir():
+		kind = "directory"
+	case mode&os.ModeNamedPipe != 0:
+		kind = "fifo"
+	case mode&os.ModeSocket != 0:
+		kind = "socket"
+	case mode&os.ModeCharDevice != 0:
+		kind = "character device"
+	case mode&os.ModeDevice != 0:
+		kind = "block device"
+	default:
+		kind = "regular file"
+	}
+	return fmt.Sprintf("(%s, %d bytes, %s)", kind, file.TargetSize, FormatFileMode(mode))
+}
+
+//Returns the quoted and colored name alone, without a symlink target
+func ColoredName(file FI.FileInfo, options OP.Options) string {
+	name := QuoteName(file.Name, options)
+	if !options.NoColor {
+		name = C.Colorize(file, file.Name, name)
 	}
 	return name
 }
@@ -84,3 +182,63 @@ func FormatFileMode(mode os.FileMode) string {
 
 	return result.String()
 }
+
+/*Returns what the invoking user may do with an entry as "rwx", with a '-' for
+every access the kernel refuses. For a directory x means it can be entered*/
+func FormatAccess(file FI.FileInfo, options OP.Options) string {
+	access := []byte("---")
+	for i, mode := range []uint32{FI.AccessRead, FI.AccessWrite, FI.AccessExecute} {
+		if FI.CanAccess(file, mode) {
+			access[i] = "rwx"[i]
+		}
+	}
+	return string(access)
+}
+
+/*Returns the character shown right after the mode string: '+' when the entry
+has a POSIX ACL, '.' when it only has a security context and nothing otherwise*/
+func ModeIndicator(file FI.FileInfo) string {
+	if file.HasACL {
+		return "+"
+	}
+	if file.HasSecurityContext() {
+		return "."
+	}
+	return ""
+}
+
+/*Returns the filesystem type shown by --fs. Mount points, entries on another
+device than the directory listing them, get a trailing '*'*/
+func FormatFsType(file FI.FileInfo) string {
+	fsType := FI.FilesystemType(file.Dev)
+	if file.IsMountPoint {
+		fsType += "*"
+	}
+	return fsType
+}
+
+/*Returns the number of entries in a directory for --count, "-" for anything
+that is not a directory and "?" for a directory that cannot be read. Hidden
+entries are counted with -a or --count=all*/
+func FormatChildCount(file FI.FileInfo, options OP.Options) string {
+	if !file.IsDir {
+		return "-"
+	}
+	count, err := file.ChildCount(options.ShowHidden || options.CountAll, options.CountDeep)
+	if err != nil {
+		return "?"
+	}
+	return fmt.Sprint(count)
+}
+
+/*Returns the hard link group of an entry as "#ID FOUND/LINKS": the group
+number, how many of its names were found in the listed tree and its link
+count. A FOUND lower than LINKS means some names live outside the tree.
+Entries with a single link show "-"*/
+func FormatHardlinkGroup(file FI.FileInfo, options OP.Options) string {
+	id, names := file.HardlinkGroup()
+	if id == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("#%d %d/%d", id, len(names), file.Nlink)
+}
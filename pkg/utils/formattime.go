@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"fmt"
+	"time"
+
+	FI "my-ls-1/pkg/fileinfo"
+	OP "my-ls-1/pkg/options"
+)
+
+// relativeWidth is the widest age FormatRelativeTime can produce ("11mo ago").
+const relativeWidth = 8
+
+//Formats the timestamp selected by --time according to --time-style
+func FormatTime(file FI.FileInfo, options OP.Options) string {
+	t := file.Time(options.TimeField)
+
+	switch options.TimeStyle {
+	case "relative":
+		return fmt.Sprintf("%*s", relativeWidth, FormatRelativeTime(t, time.Now()))
+	case "full-iso":
+		return t.Format("2006-01-02 15:04:05.000000000 -0700")
+	case "long-iso":
+		return t.Format("2006-01-02 15:04")
+	case "iso":
+		if t.Before(time.Now().AddDate(0, -6, 0)) {
+			return t.Format("2006-01-02 ")
+		}
+		return t.Format("01-02 15:04")
+	}
+
+	timeFormat := "Jan _2 15:04"
+	sixMonthsAgo := time.Now().AddDate(0, -6, 0)
+	if t.Before(sixMonthsAgo) {
+		timeFormat = "Jan _2  2006"
+	}
+	return t.Format(timeFormat)
+}
+
+/*Returns the age of t relative to now in the largest whole unit, for example
+"5m ago", "3h ago" or "in 4h" for timestamps in the future*/
+func FormatRelativeTime(t, now time.Time) string {
+	d := now.Sub(t)
+	future := d < 0
+	if future {
+		d = -d
+	}
+
+	var age string
+	switch {
+	case d < time.Minute:
+		age = fmt.Sprintf("%ds", int(d/time.Second))
+	case d < time.Hour:
+		age = fmt.Sprintf("%dm", int(d/time.Minute))
+	case d < 24*time.Hour:
+		age = fmt.Sprintf("%dh", int(d/time.Hour))
+	case d < 7*24*time.Hour:
+		age = fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+	case d < 30*24*time.Hour:
+		age = fmt.Sprintf("%dw", int(d/(7*24*time.Hour)))
+	case d < 365*24*time.Hour:
+		age = fmt.Sprintf("%dmo", int(d/(30*24*time.Hour)))
+	default:
+		age = fmt.Sprintf("%dy", int(d/(365*24*time.Hour)))
+	}
+
+	if future {
+		return "in " + age
+	}
+	return age + " ago"
+}
@@ -0,0 +1,293 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+
+	H "my-ls-1/pkg/checksum"
+	FI "my-ls-1/pkg/fileinfo"
+	OP "my-ls-1/pkg/options"
+	W "my-ls-1/pkg/utils/width"
+)
+
+// How the cells of a column are padded to the column width.
+const (
+	AlignLeft = iota
+	AlignRight
+)
+
+/*A Column is one field of the long format. Cells returns the text of the
+column for every entry of a listing at once, so a column can line up parts of
+its cells across the listing, like the device numbers in the size column*/
+type Column struct {
+	Name   string
+	Header string
+	Align  int
+	Cells  func(files []FI.FileInfo, options OP.Options) []string
+}
+
+// columns holds every column --columns can name, see RegisterColumn.
+var columns = map[string]Column{}
+
+/*Makes a column available to --columns. Registering a name twice replaces
+the earlier column*/
+func RegisterColumn(column Column) {
+	columns[column.Name] = column
+}
+
+//Returns the registered column with the given name
+func LookupColumn(name string) (Column, bool) {
+	column, ok := columns[name]
+	return column, ok
+}
+
+/*Returns the names of the columns the long format shows. --columns picks them
+explicitly, otherwise the classic layout is used, trimmed by -g, -o and -G and
+extended by --octal, --access, --attrs, --hardlinks, -Z, --fs, --count,
+--checksum and, in the table styles, -@*/
+func LongColumns(options OP.Options) []string {
+	if len(options.Columns) > 0 {
+		return options.Columns
+	}
+
+	var layout []string
+	switch options.Octal {
+	case "only":
+		layout = []string{"octal"}
+	case "beside":
+		layout = []string{"mode", "octal"}
+	default:
+		layout = []string{"mode"}
+	}
+	if options.Access {
+		layout = append(layout, "access")
+	}
+	if options.Attrs {
+		layout = append(layout, "attrs")
+	}
+	layout = append(layout, "links")
+	if options.Hardlinks != "" {
+		layout = append(layout, "hardlinks")
+	}
+	if !options.NoOwner {
+		layout = append(layout, "user")
+	}
+	if !options.NoGroup {
+		layout = append(layout, "group")
+	}
+	if options.Context {
+		layout = append(layout, "context")
+	}
+	if options.FsType {
+		layout = append(layout, "fs")
+	}
+	layout = append(layout, "size")
+	if options.Count {
+		layout = append(layout, "count")
+	}
+
+	switch options.TimeField {
+	case "atime", "ctime":
+		layout = append(layout, options.TimeField)
+	default:
+		layout = append(layout, "mtime")
+	}
+
+	if options.Checksum != "" {
+		layout = append(layout, "checksum")
+	}
+	layout = append(layout, "name")
+
+	// the plain style lists the attributes below each line instead
+	if options.Xattrs && options.TableStyle != "" && options.TableStyle != "plain" {
+		layout = append(layout, "xattrs")
+	}
+	return layout
+}
+
+// perFile adapts a function formatting a single entry to Column.Cells.
+func perFile(format func(file FI.FileInfo, options OP.Options) string) func([]FI.FileInfo, OP.Options) []string {
+	return func(files []FI.FileInfo, options OP.Options) []string {
+		cells := make([]string, len(files))
+		for i, file := range files {
+			cells[i] = format(file, options)
+		}
+		return cells
+	}
+}
+
+// timeCells formats one of the timestamps whatever --time selected.
+func timeCells(field string) func([]FI.FileInfo, OP.Options) []string {
+	return perFile(func(file FI.FileInfo, options OP.Options) string {
+		options.TimeField = field
+		return FormatTime(file, options)
+	})
+}
+
+func init() {
+	RegisterColumn(Column{Name: "inode", Header: "Inode", Align: AlignRight,
+		Cells: perFile(func(file FI.FileInfo, options OP.Options) string {
+			return fmt.Sprint(file.Ino)
+		})})
+	// entries without an indicator get padded, the column only widens when one exists
+	RegisterColumn(Column{Name: "mode", Header: "Mode", Align: AlignLeft,
+		Cells: perFile(func(file FI.FileInfo, options OP.Options) string {
+			return FormatFileMode(file.Mode) + ModeIndicator(file)
+		})})
+	RegisterColumn(Column{Name: "octal", Header: "Octal", Align: AlignLeft,
+		Cells: perFile(func(file FI.FileInfo, options OP.Options) string {
+			return fmt.Sprintf("%04o", FI.UnixMode(file.Mode))
+		})})
+	RegisterColumn(Column{Name: "access", Header: "Access", Align: AlignLeft,
+		Cells: perFile(FormatAccess)})
+	RegisterColumn(Column{Name: "attrs", Header: "Attributes", Align: AlignLeft,
+		Cells: perFile(func(file FI.FileInfo, options OP.Options) string {
+			return FormatInodeFlags(file)
+		})})
+	RegisterColumn(Column{Name: "links", Header: "Links", Align: AlignRight,
+		Cells: perFile(func(file FI.FileInfo, options OP.Options) string {
+			return fmt.Sprint(file.Nlink)
+		})})
+	RegisterColumn(Column{Name: "hardlinks", Header: "Hardlinks", Align: AlignLeft,
+		Cells: perFile(FormatHardlinkGroup)})
+	RegisterColumn(Column{Name: "user", Header: "User", Align: AlignLeft,
+		Cells: perFile(func(file FI.FileInfo, options OP.Options) string {
+			return FI.UserName(file.Uid)
+		})})
+	RegisterColumn(Column{Name: "group", Header: "Group", Align: AlignLeft,
+		Cells: perFile(func(file FI.FileInfo, options OP.Options) string {
+			return FI.GroupName(file.Gid)
+		})})
+	RegisterColumn(Column{Name: "context", Header: "Context", Align: AlignLeft,
+		Cells: perFile(func(file FI.FileInfo, options OP.Options) string {
+			return file.SecurityContext()
+		})})
+	RegisterColumn(Column{Name: "fs", Header: "Filesystem", Align: AlignLeft,
+		Cells: perFile(func(file FI.FileInfo, options OP.Options) string {
+			return FormatFsType(file)
+		})})
+	RegisterColumn(Column{Name: "size", Header: "Size", Align: AlignRight, Cells: sizeCells})
+	RegisterColumn(Column{Name: "blocks", Header: "Blocks", Align: AlignRight,
+		Cells: perFile(func(file FI.FileInfo, options OP.Options) string {
+			return fmt.Sprint(file.Blocks)
+		})})
+	RegisterColumn(Column{Name: "count", Header: "Count", Align: AlignRight,
+		Cells: perFile(FormatChildCount)})
+	RegisterColumn(Column{Name: "mtime", Header: "Modified", Align: AlignLeft, Cells: timeCells("mtime")})
+	RegisterColumn(Column{Name: "atime", Header: "Accessed", Align: AlignLeft, Cells: timeCells("atime")})
+	RegisterColumn(Column{Name: "ctime", Header: "Changed", Align: AlignLeft, Cells: timeCells("ctime")})
+	RegisterColumn(Column{Name: "checksum", Header: "Checksum", Align: AlignLeft, Cells: checksumCells})
+	RegisterColumn(Column{Name: "name", Header: "Name", Align: AlignLeft, Cells: nameCells})
+	RegisterColumn(Column{Name: "xattrs", Header: "Xattrs", Align: AlignLeft,
+		Cells: perFile(FormatXattrs)})
+	RegisterColumn(Column{Name: "target", Header: "Target", Align: AlignLeft,
+		Cells: perFile(func(file FI.FileInfo, options OP.Options) string {
+			if !file.IsLink {
+				return ""
+			}
+			return FormatLinkTarget(file, options)
+		})})
+}
+
+/*The size in bytes, or "major, minor" for devices with both numbers lined up
+across the listing*/
+func sizeCells(files []FI.FileInfo, options OP.Options) []string {
+	maxMajorWidth := 0
+	maxMinorWidth := 0
+	for _, file := range files {
+		if file.Mode&os.ModeDevice != 0 {
+			maxMajorWidth = max(maxMajorWidth, len(fmt.Sprint(Major(file.Rdev))))
+			maxMinorWidth = max(maxMinorWidth, len(fmt.Sprint(Minor(file.Rdev))))
+		}
+	}
+
+	cells := make([]string, len(files))
+	for i, file := range files {
+		if file.Mode&os.ModeDevice != 0 {
+			cells[i] = fmt.Sprintf("%*d, %*d", maxMajorWidth, Major(file.Rdev), maxMinorWidth, Minor(file.Rdev))
+		} else {
+			cells[i] = fmt.Sprint(file.Size)
+		}
+	}
+	return cells
+}
+
+/*The content checksum of every regular file, hashed in parallel. Other
+entries show "-", files above --checksum-max-size "(too large)" and files
+that could not be read "?"*/
+func checksumCells(files []FI.FileInfo, options OP.Options) []string {
+	algorithm := options.Checksum
+	if algorithm == "" {
+		algorithm = "sha256"
+	}
+
+	cells := make([]string, len(files))
+	for i, result := range H.Files(files, algorithm, options.MaxHashed) {
+		switch {
+		case result.Err == H.ErrTooLarge:
+			cells[i] = "(too large)"
+		case result.Err != nil:
+			cells[i] = "?"
+		case result.Sum == "":
+			cells[i] = "-"
+		default:
+			cells[i] = result.Sum
+		}
+	}
+	return cells
+}
+
+/*The quoted, colored name. The " -> target" part is left out when the layout
+shows the target in a column of its own*/
+func nameCells(files []FI.FileInfo, options OP.Options) []string {
+	splitTarget := false
+	for _, name := range LongColumns(options) {
+		if name == "target" {
+			splitTarget = true
+		}
+	}
+
+	cells := make([]string, len(files))
+	for i, file := range files {
+		if splitTarget {
+			cells[i] = ColoredName(file, options)
+		} else {
+			cells[i] = FormatFileName(file, options)
+		}
+	}
+	return cells
+}
+
+/*Computes every column of the long format for a listing. Returns the columns
+in display order, their cells and the width of each column in terminal cells.
+With --header the widths leave room for the column headers. Every table style
+lays out its rows from these widths*/
+func longTable(files []FI.FileInfo, options OP.Options) ([]Column, [][]string, []int) {
+	var layout []Column
+	for _, name := range LongColumns(options) {
+		if column, ok := LookupColumn(name); ok {
+			layout = append(layout, column)
+		}
+	}
+
+	cells := make([][]string, len(layout))
+	for c, column := range layout {
+		cells[c] = column.Cells(files, options)
+	}
+	return layout, cells, columnWidths(layout, cells, options)
+}
+
+/*Returns the width of every column in terminal cells, the header included
+with --header. Styles that rewrite the cells measure them again afterwards*/
+func columnWidths(layout []Column, cells [][]string, options OP.Options) []int {
+	widths := make([]int, len(layout))
+	for c, column := range layout {
+		if options.Header {
+			widths[c] = W.DisplayWidth(column.Header)
+		}
+		for _, cell := range cells[c] {
+			widths[c] = max(widths[c], W.DisplayWidth(cell))
+		}
+	}
+	return widths
+}
@@ -1,55 +1,19 @@
 package utils
 
 import (
-	"fmt"
 	"os"
 	"strings"
 
 	FI "my-ls-1/pkg/fileinfo"
-	OP "my-ls-1/pkg/options"
 )
 
-// This function calculates the total size in 1 KB blocks for the specified directory's entries only.
-func calculateTotalBlocks(dir string, options OP.Options) (int64, error) {
-	var totalBlocks int64
-	var files []FI.FileInfo
-	var hiddens []os.DirEntry
-
-	entries, err := os.ReadDir(dir)
-	if err != nil {
-		return 0, err
-	}
-
-	for _, entry := range entries {
-		if IsHidden(entry) {
-			hiddens = append(hiddens, entry)
-		}
-	}
-
-	if options.ShowHidden {
-		AddSpecialEntry(dir, ".", &files)
-		AddSpecialEntry(fmt.Sprintf("%s/%s", dir, ".."), "..", &files)
-		for _, ety := range hiddens {
-			AddSpecialEntry(fmt.Sprintf("%s/%s", dir, ety.Name()), ety.Name(), &files)
-		}
-	}
-
-	for _, entry := range entries {
-		if !IsHidden(entry) {
-			info, err := entry.Info()
-			if err != nil {
-				continue
-			}
-			fileInfo := FI.CreateFileInfo(dir, info)
-			files = append(files, fileInfo)
-		}
-	}
-
+//Sums the size in 1 KB blocks of the entries of a listing
+func totalBlocks(files []FI.FileInfo) int64 {
+	var total int64
 	for _, file := range files {
-		totalBlocks += file.Blocks
+		total += file.Blocks
 	}
-
-	return totalBlocks, nil
+	return total
 }
 
 func AddSpecialEntry(path, name string, files *[]FI.FileInfo) {
@@ -64,14 +28,7 @@ func AddSpecialEntry(path, name string, files *[]FI.FileInfo) {
 
 // / IsHidden checks if a given DirEntry is a hidden directory/file.
 func IsHidden(entry os.DirEntry) bool {
-
-	if !entry.IsDir() {
-		return false
-	}
-
-	dirName := entry.Name()
-
-	return strings.HasPrefix(dirName, ".")
+	return strings.HasPrefix(entry.Name(), ".")
 }
 
 // GetDir returns the directory part of a file path
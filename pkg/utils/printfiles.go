@@ -3,19 +3,20 @@ package utils
 import (
 	"fmt"
 	"os"
-	"os/user"
+	"path/filepath"
 	"strings"
-	"time"
 
 	T "my-ls-1/cmd/terminal"
 	FI "my-ls-1/pkg/fileinfo"
 	OP "my-ls-1/pkg/options"
+	W "my-ls-1/pkg/utils/width"
 )
 
 var Path string
 
 //This function will print entries in the long format. (ls -l)
 func PrintLongFormat(files []FI.FileInfo, options OP.Options) {
+	showTotal := false
 
 	if len(os.Args) > 2 {
 
@@ -43,159 +44,194 @@ func PrintLongFormat(files []FI.FileInfo, options OP.Options) {
 							path = fmt.Sprintf("%s/%s", PATH, arg)
 							Path = path
 						}
-
-						totalBlocks, _ := calculateTotalBlocks(path, options)
-						fmt.Printf("total %d\n", totalBlocks)
+						showTotal = true
 					}
 				}
 			}
 		}
 
 	} else if len(os.Args) == 2 {
-		var path string
-		if isStandardLibrary(os.Args[1]) {
-			path = os.Args[1]
-		} else {
+		if !isStandardLibrary(os.Args[1]) {
 			PATH, _ := os.Getwd()
-			path = PATH
-			Path = path
+			Path = PATH
 		}
-		totalBlocks, _ := calculateTotalBlocks(path, options)
-		fmt.Printf("total %d\n", totalBlocks)
+		showTotal = true
 	}
 
-	maxNlinkWidth := 0
-	maxUserWidth := 0
-	maxGroupWidth := 0
-	maxSizeWidth := 0
-	maxMajorWidth := 0
-	maxMinorWidth := 0
-
-	// printing the number of hardlinks of a specific file.
-	for _, file := range files {
+	// the total covers the entries shown, so filters and -a are accounted for
+	if showTotal {
+		printTotal(totalBlocks(files), options)
+	}
 
-		nlinkWidth := len(fmt.Sprintf("%d", file.Nlink))
-		if nlinkWidth > maxNlinkWidth {
-			maxNlinkWidth = nlinkWidth
-		}
+	if val, _ := IsSymlink(Path); val {
+		files, _ = GetSymlinksInDir(fmt.Sprintf("%s/..", Path))
+	}
 
-		usr, _ := user.LookupId(fmt.Sprint(file.Uid))
-		userWidth := len(usr.Username)
-		if userWidth > maxUserWidth {
-			maxUserWidth = userWidth
-		}
+	switch options.TableStyle {
+	case "grid":
+		printGrid(files, options)
+	case "markdown":
+		printMarkdown(files, options)
+	case "csv", "tsv":
+		printDelimited(files, options)
+	default:
+		printPlain(files, options)
+	}
+}
 
-		grp, _ := user.LookupGroupId(fmt.Sprint(file.Gid))
-		groupWidth := len(grp.Name)
-		if groupWidth > maxGroupWidth {
-			maxGroupWidth = groupWidth
-		}
-
-		if file.Mode&os.ModeDevice != 0 {
-			major := Major(file.Rdev)
-			minor := Minor(file.Rdev)
-			majorWidth := len(fmt.Sprintf("%d", major))
-			minorWidth := len(fmt.Sprintf("%d", minor))
-			if majorWidth > maxMajorWidth {
-				maxMajorWidth = majorWidth
-			}
-			if minorWidth > maxMinorWidth {
-				maxMinorWidth = minorWidth
-			}
-		} else {
-			sizeWidth := len(fmt.Sprintf("%d", file.Size))
-			if sizeWidth > maxSizeWidth {
-				maxSizeWidth = sizeWidth
-			}
+/*Prints the other names of the tree that share the inode of an entry below
+its long format line, for --hardlinks=names*/
+func PrintHardlinkNames(file FI.FileInfo, options OP.Options) {
+	_, names := file.HardlinkGroup()
+	self := filepath.Clean(file.Path)
+	for _, name := range names {
+		if name != self {
+			fmt.Printf("\t= %s\n", QuoteName(name, options))
 		}
 	}
-	if val, _ := IsSymlink(Path); val {
-		files, _ = GetSymlinksInDir(fmt.Sprintf("%s/..", Path))
+}
+
+/*This function will format the files in the terminal correctly. Every column
+is only as wide as its own widest name, and the layout with the most columns
+that still fits the terminal is chosen. Entries run down the columns, or
+across the rows with -x*/
+func PrintColumnar(files []FI.FileInfo, options OP.Options) {
+	if len(files) == 0 {
+		return
 	}
 
-	for _, file := range files {
-		usr, _ := user.LookupId(fmt.Sprint(file.Uid))
-		grp, _ := user.LookupGroupId(fmt.Sprint(file.Gid))
+	termWidth := terminalWidth()
 
-		modeStr := FormatFileMode(file.Mode)
+	names := ShortEntries(files, options)
+	widths := make([]int, len(files))
+	for i, name := range names {
+		widths[i] = W.DisplayWidth(name)
+	}
 
-		size := ""
-		if file.Mode&os.ModeDevice != 0 {
-			major := Major(file.Rdev)
-			minor := Minor(file.Rdev)
-			size = fmt.Sprintf("%*d, %*d", maxMajorWidth, major, maxMinorWidth, minor) // this is a device
-		} else {
-			size = fmt.Sprintf("%*d", maxSizeWidth, file.Size) // normal directory
-		}
+	numCols, colWidths := fitColumns(widths, termWidth, options.Across)
+	numRows := (len(files) + numCols - 1) / numCols
 
-		fileName := FormatFileName(file, options)
+	for row := 0; row < numRows; row++ {
+		pos := 0
+		for col := 0; col < numCols; col++ {
+			idx := cellIndex(row, col, numRows, numCols, options.Across)
+			fmt.Print(names[idx])
 
-		timeFormat := "Jan _2 15:04"
-		sixMonthsAgo := time.Now().AddDate(0, -6, 0)
-		if file.ModTime.Before(sixMonthsAgo) {
-			timeFormat = "Jan _2  2006"
+			next := cellIndex(row, col+1, numRows, numCols, options.Across)
+			if col+1 == numCols || next >= len(files) {
+				break
+			}
+			fmt.Print(indent(pos+widths[idx], pos+colWidths[col], options.TabSize))
+			pos += colWidths[col]
 		}
+		fmt.Println()
+	}
+}
 
-		fmt.Printf("%s %*d %-*s %-*s %*s %s %s\n",
-			modeStr,
-			maxNlinkWidth, file.Nlink,
-			maxUserWidth, usr.Username,
-			maxGroupWidth, grp.Name,
-			maxSizeWidth+maxMajorWidth+maxMinorWidth, size,
-			file.ModTime.Format(timeFormat),
-			fileName,
-		)
+//Returns the width of the terminal, 80 columns when it can not be determined
+func terminalWidth() int {
+	if width := T.GetTerminalWidth(); width >= 1 {
+		return width
 	}
+	return 80
 }
 
-//This function will format the files in the terminal correctly, based on the column width
-func PrintColumnar(files []FI.FileInfo, options OP.Options) {
-	termWidth := T.GetTerminalWidth()
-	if termWidth < 1 {
-		termWidth = 80
+/*This function prints the entries as one comma separated stream (ls -m),
+starting a new line whenever the next name would not fit the terminal*/
+func PrintCommas(files []FI.FileInfo, options OP.Options) {
+	if len(files) == 0 {
+		return
 	}
 
-	maxWidth := 0
-	for _, file := range files {
-		width := len(FormatFileName(file, options))
-		if width > maxWidth {
-			maxWidth = width
+	termWidth := terminalWidth()
+	pos := 0
+	for i, name := range ShortEntries(files, options) {
+		width := W.DisplayWidth(name)
+		if i > 0 {
+			if pos+width+2 < termWidth {
+				fmt.Print(", ")
+				pos += 2
+			} else {
+				fmt.Print(",\n")
+				pos = 0
+			}
 		}
+		fmt.Print(name)
+		pos += width
 	}
+	fmt.Println()
+}
 
-	colWidth := maxWidth + 2
-	numCols := termWidth / colWidth
-	if numCols == 0 {
-		numCols = 1
+/*Returns the text shown for every entry in the short formats: the formatted
+name, with --count the number of entries of a directory after it and, with -Z,
+the security context aligned in front of it*/
+func ShortEntries(files []FI.FileInfo, options OP.Options) []string {
+	contextWidth := 0
+	if options.Context && !options.Commas {
+		for _, file := range files {
+			if width := W.DisplayWidth(file.SecurityContext()); width > contextWidth {
+				contextWidth = width
+			}
+		}
 	}
 
-	numRows := (len(files) + numCols - 1) / numCols
-
-	for i := 0; i < numRows; i++ {
-		for j := 0; j < numCols; j++ {
-			idx := j*numRows + i
-			if idx < len(files) {
-				fileName := FormatFileName(files[idx], options)
-				fmt.Printf("%-*s", colWidth, fileName)
-			}
+	entries := make([]string, len(files))
+	for i, file := range files {
+		entries[i] = FormatFileName(file, options)
+		if options.Count && file.IsDir {
+			entries[i] += " (" + FormatChildCount(file, options) + ")"
+		}
+		if options.Context {
+			entries[i] = W.PadLeft(file.SecurityContext(), contextWidth) + " " + entries[i]
 		}
-		fmt.Println()
 	}
+	return entries
 }
 
 //This functions lists entries to the console based on the option long format
 func PrintFiles(files []FI.FileInfo, options OP.Options) {
 	if options.LongFormat {
 		PrintLongFormat(files, options)
-	} else if options.OnePerLine {
-		for _, file := range files {
-			fmt.Println(FormatFileName(file, options))
+	} else if options.OnePerLine || options.Xattrs {
+		// the attributes of an entry are listed below it, so -@ implies -1
+		for i, name := range ShortEntries(files, options) {
+			fmt.Println(name)
+			if options.Xattrs {
+				PrintXattrs(files[i])
+			}
 		}
+	} else if options.Commas {
+		PrintCommas(files, options)
 	} else {
 		PrintColumnar(files, options)
 	}
 }
 
+//Lists the extended attributes of an entry and their sizes below it (ls -l@)
+func PrintXattrs(file FI.FileInfo) {
+	for _, name := range file.Xattrs {
+		size, err := FI.XattrSize(file.Path, name)
+		if err != nil {
+			continue
+		}
+		fmt.Printf("\t%s\t%6d\n", QuoteName(name, OP.Options{Quoting: "escape"}), size)
+	}
+}
+
+/*Returns the extended attributes of an entry on a single line as
+"name=size" pairs separated by commas, for the xattrs column*/
+func FormatXattrs(file FI.FileInfo, options OP.Options) string {
+	var attrs []string
+	for _, name := range file.Xattrs {
+		size, err := FI.XattrSize(file.Path, name)
+		if err != nil {
+			continue
+		}
+		attrs = append(attrs, fmt.Sprintf("%s=%d", QuoteName(name, OP.Options{Quoting: "escape"}), size))
+	}
+	return strings.Join(attrs, ",")
+}
+
 /*Extract major and minor device numbers from a uintptr,
 which typically represents the underlying system's device information.*/
 func Major(dev uint64) uint64 {
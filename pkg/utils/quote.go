@@ -0,0 +1,165 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	OP "my-ls-1/pkg/options"
+)
+
+// shellSpecial are the characters that make a name need quoting in a shell.
+const shellSpecial = " \t\n!\"#$&'()*;<=>?[\\]^`{|}"
+
+/*This function quotes a file name according to --quoting-style so control
+characters, newlines and invalid UTF-8 can never reach the terminal raw*/
+func QuoteName(name string, options OP.Options) string {
+	switch options.Quoting {
+	case "c":
+		return `"` + escapeC(name, `"`) + `"`
+	case "escape":
+		return escapeC(name, " ")
+	case "shell-escape":
+		return quoteShellEscape(name)
+	case "shell":
+		return quoteShell(hideControl(name, options), false)
+	case "shell-always":
+		return quoteShell(hideControl(name, options), true)
+	default:
+		return hideControl(name, options)
+	}
+}
+
+//Replaces every nonprintable character with '?' when -q was given
+func hideControl(name string, options OP.Options) string {
+	if !options.HideCtrl {
+		return name
+	}
+	var b strings.Builder
+	for i := 0; i < len(name); {
+		r, size := utf8.DecodeRuneInString(name[i:])
+		if isPrintable(r, size) {
+			b.WriteString(name[i : i+size])
+		} else {
+			b.WriteByte('?')
+		}
+		i += size
+	}
+	return b.String()
+}
+
+/*Wraps a name in quotes when the shell would otherwise split or expand it.
+Names containing a single quote use double quotes when that is safe*/
+func quoteShell(name string, always bool) string {
+	if !always && name != "" && !strings.ContainsAny(name, shellSpecial) && name[0] != '~' {
+		return name
+	}
+	if strings.Contains(name, "'") && !strings.ContainsAny(name, "$`\\\"!") {
+		return `"` + name + `"`
+	}
+	return "'" + strings.ReplaceAll(name, "'", `'\''`) + "'"
+}
+
+/*Like quoteShell, but nonprintable characters are written as $'\n' style
+ANSI-C strings between the quoted printable parts*/
+func quoteShellEscape(name string) string {
+	var parts []string
+	var printable, escaped strings.Builder
+
+	flushPrintable := func() {
+		if printable.Len() > 0 {
+			parts = append(parts, printable.String())
+			printable.Reset()
+		}
+	}
+	flushEscaped := func() {
+		if escaped.Len() > 0 {
+			parts = append(parts, "$'"+escaped.String()+"'")
+			escaped.Reset()
+		}
+	}
+
+	for i := 0; i < len(name); {
+		r, size := utf8.DecodeRuneInString(name[i:])
+		if isPrintable(r, size) {
+			flushEscaped()
+			printable.WriteString(name[i : i+size])
+		} else {
+			flushPrintable()
+			escaped.WriteString(escapeBytes(r, name[i:i+size]))
+		}
+		i += size
+	}
+	flushEscaped()
+	flushPrintable()
+
+	if len(parts) == 1 && !strings.HasPrefix(parts[0], "$'") {
+		return quoteShell(parts[0], false)
+	}
+
+	var b strings.Builder
+	for _, part := range parts {
+		if strings.HasPrefix(part, "$'") {
+			b.WriteString(part)
+		} else {
+			b.WriteString(quoteShell(part, true))
+		}
+	}
+	return b.String()
+}
+
+/*Escapes a name with C backslash sequences. Nonprintable characters and
+invalid bytes become octal escapes, extra lists more characters to escape*/
+func escapeC(name, extra string) string {
+	var b strings.Builder
+	for i := 0; i < len(name); {
+		r, size := utf8.DecodeRuneInString(name[i:])
+		switch {
+		case r == '\\':
+			b.WriteString(`\\`)
+		case size == 1 && strings.ContainsRune(extra, r):
+			b.WriteString(`\` + string(r))
+		case isPrintable(r, size):
+			b.WriteString(name[i : i+size])
+		default:
+			b.WriteString(escapeBytes(r, name[i:i+size]))
+		}
+		i += size
+	}
+	return b.String()
+}
+
+//Returns the C escape sequence for a nonprintable character
+func escapeBytes(r rune, raw string) string {
+	switch r {
+	case '\a':
+		return `\a`
+	case '\b':
+		return `\b`
+	case '\f':
+		return `\f`
+	case '\n':
+		return `\n`
+	case '\r':
+		return `\r`
+	case '\t':
+		return `\t`
+	case '\v':
+		return `\v`
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(raw); i++ {
+		fmt.Fprintf(&b, `\%03o`, raw[i])
+	}
+	return b.String()
+}
+
+// An invalid byte decodes as a one byte RuneError and is never printable.
+func isPrintable(r rune, size int) bool {
+	if r == utf8.RuneError && size == 1 {
+		return false
+	}
+	return unicode.IsPrint(r)
+}
@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	FI "my-ls-1/pkg/fileinfo"
+)
+
+// Summary aggregates the entries of one or more listings for --summary.
+type Summary struct {
+	Files    int
+	Dirs     int
+	Links    int
+	Specials int
+	Bytes    int64
+	Blocks   int64 // allocated size in 1 KB blocks
+	Newest   time.Time
+	Oldest   time.Time
+}
+
+/*Returns the summary of a listing. The "." and ".." entries shown by -a are
+left out, they are not part of what the directory holds*/
+func Summarize(files []FI.FileInfo) Summary {
+	var summary Summary
+	for _, file := range files {
+		if file.Name == "." || file.Name == ".." {
+			continue
+		}
+
+		switch {
+		case file.IsDir:
+			summary.Dirs++
+		case file.IsLink:
+			summary.Links++
+		case file.Mode&(os.ModeDevice|os.ModeNamedPipe|os.ModeSocket) != 0:
+			summary.Specials++
+		default:
+			summary.Files++
+		}
+
+		summary.Bytes += file.Size
+		summary.Blocks += file.Blocks
+		summary.addTime(file.ModTime)
+	}
+	return summary
+}
+
+//Adds the counts and sizes of another summary to this one
+func (s *Summary) Merge(other Summary) {
+	s.Files += other.Files
+	s.Dirs += other.Dirs
+	s.Links += other.Links
+	s.Specials += other.Specials
+	s.Bytes += other.Bytes
+	s.Blocks += other.Blocks
+	if !other.Newest.IsZero() {
+		s.addTime(other.Newest)
+		s.addTime(other.Oldest)
+	}
+}
+
+func (s *Summary) addTime(t time.Time) {
+	if s.Newest.IsZero() || t.After(s.Newest) {
+		s.Newest = t
+	}
+	if s.Oldest.IsZero() || t.Before(s.Oldest) {
+		s.Oldest = t
+	}
+}
+
+//Prints a summary as a single line starting with label
+func PrintSummary(label string, s Summary) {
+	fmt.Printf("%s: %d files, %d directories, %d symlinks, %d special; %d bytes, %d allocated",
+		label, s.Files, s.Dirs, s.Links, s.Specials, s.Bytes, s.Blocks*1024)
+	if !s.Newest.IsZero() {
+		fmt.Printf("; newest %s, oldest %s",
+			s.Newest.Format("2006-01-02 15:04"), s.Oldest.Format("2006-01-02 15:04"))
+	}
+	fmt.Println()
+}
@@ -0,0 +1,226 @@
+package utils
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	FI "my-ls-1/pkg/fileinfo"
+	OP "my-ls-1/pkg/options"
+	W "my-ls-1/pkg/utils/width"
+)
+
+/*Prints the "total N" line of the long format. The delimited and Markdown
+table styles leave it out so their output stays a clean table*/
+func printTotal(totalBlocks int64, options OP.Options) {
+	switch options.TableStyle {
+	case "markdown", "csv", "tsv":
+		return
+	}
+	fmt.Printf("total %d\n", totalBlocks)
+}
+
+/*The classic long format: columns separated by one space, numbers aligned to
+the right. Trailing empty cells are dropped and the last cell is never padded*/
+func printPlain(files []FI.FileInfo, options OP.Options) {
+	layout, cells, widths := longTable(files, options)
+
+	if options.Header {
+		fmt.Println(plainRow(layout, headerRow(layout), widths))
+	}
+
+	for i, file := range files {
+		row := make([]string, len(layout))
+		for c := range layout {
+			row[c] = cells[c][i]
+		}
+		fmt.Println(plainRow(layout, row, widths))
+
+		if options.Xattrs {
+			PrintXattrs(file)
+		}
+		if options.Hardlinks == "names" {
+			PrintHardlinkNames(file, options)
+		}
+		if options.Explain {
+			PrintPermissionExplanation(file)
+		}
+	}
+}
+
+func plainRow(layout []Column, row []string, widths []int) string {
+	last := len(row) - 1
+	for last > 0 && row[last] == "" {
+		last--
+	}
+
+	var line strings.Builder
+	for c, cell := range row[:last+1] {
+		if c > 0 {
+			line.WriteByte(' ')
+		}
+		if c < last {
+			cell = alignCell(cell, layout[c], widths[c])
+		}
+		line.WriteString(cell)
+	}
+	return line.String()
+}
+
+//Draws the columns as a bordered ASCII table
+func printGrid(files []FI.FileInfo, options OP.Options) {
+	layout, cells, widths := longTable(files, options)
+
+	var border strings.Builder
+	border.WriteByte('+')
+	for _, width := range widths {
+		border.WriteString(strings.Repeat("-", width+2) + "+")
+	}
+
+	row := func(cells []string) {
+		var line strings.Builder
+		line.WriteByte('|')
+		for c, cell := range cells {
+			line.WriteString(" " + alignCell(cell, layout[c], widths[c]) + " |")
+		}
+		fmt.Println(line.String())
+	}
+
+	fmt.Println(border.String())
+	if options.Header {
+		row(headerRow(layout))
+		fmt.Println(border.String())
+	}
+	for i := range files {
+		row(columnCells(cells, i))
+	}
+	fmt.Println(border.String())
+}
+
+/*Prints a Markdown table for pasting into documents and tickets. Markdown
+needs a header row, so one is always printed, and colors are left out*/
+func printMarkdown(files []FI.FileInfo, options OP.Options) {
+	options.NoColor = true
+	options.Header = true
+	options.Quoting = "literal"
+	layout, cells, _ := longTable(files, options)
+
+	// cells are escaped before measuring so the escapes are part of the width
+	for c := range cells {
+		for i := range cells[c] {
+			cells[c][i] = escapeMarkdown(cells[c][i])
+		}
+	}
+	widths := columnWidths(layout, cells, options)
+	for c := range widths {
+		// the rule below the header needs at least three dashes
+		widths[c] = max(widths[c], 3)
+	}
+
+	row := func(cells []string) {
+		var line strings.Builder
+		line.WriteByte('|')
+		for c, cell := range cells {
+			line.WriteString(" " + alignCell(cell, layout[c], widths[c]) + " |")
+		}
+		fmt.Println(line.String())
+	}
+
+	row(headerRow(layout))
+
+	var rule strings.Builder
+	rule.WriteByte('|')
+	for c, column := range layout {
+		dashes := strings.Repeat("-", widths[c])
+		if column.Align == AlignRight {
+			dashes = dashes[1:] + ":"
+		}
+		rule.WriteString(" " + dashes + " |")
+	}
+	fmt.Println(rule.String())
+
+	for i := range files {
+		row(columnCells(cells, i))
+	}
+}
+
+/*Prints comma or tab separated values without padding or colors. CSV fields
+are quoted as RFC 4180 requires. TSV has no quoting, so backslashes, tabs and
+line breaks inside a cell are written as \\, \t, \n and \r instead*/
+func printDelimited(files []FI.FileInfo, options OP.Options) {
+	options.NoColor = true
+	options.Quoting = "literal"
+	layout, cells, _ := longTable(files, options)
+
+	var rows [][]string
+	if options.Header {
+		rows = append(rows, headerRow(layout))
+	}
+	for i := range files {
+		rows = append(rows, columnCells(cells, i))
+	}
+
+	if options.TableStyle == "csv" {
+		writer := csv.NewWriter(os.Stdout)
+		writer.WriteAll(rows)
+		return
+	}
+
+	escape := strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+	for _, row := range rows {
+		for c := range row {
+			row[c] = escape.Replace(row[c])
+		}
+		fmt.Println(strings.Join(row, "\t"))
+	}
+}
+
+/*Escapes a cell for a Markdown table: backslashes and pipes get a backslash,
+control characters are spelled out like in the tsv output*/
+func escapeMarkdown(cell string) string {
+	var escaped strings.Builder
+	for _, r := range cell {
+		switch {
+		case r == '\\' || r == '|':
+			escaped.WriteRune('\\')
+			escaped.WriteRune(r)
+		case r == '\t':
+			escaped.WriteString(`\t`)
+		case r == '\n':
+			escaped.WriteString(`\n`)
+		case r == '\r':
+			escaped.WriteString(`\r`)
+		case unicode.IsControl(r):
+			fmt.Fprintf(&escaped, `\x%02x`, r)
+		default:
+			escaped.WriteRune(r)
+		}
+	}
+	return escaped.String()
+}
+
+func headerRow(layout []Column) []string {
+	row := make([]string, len(layout))
+	for c, column := range layout {
+		row[c] = column.Header
+	}
+	return row
+}
+
+// Returns the cells of one entry, one per column.
+func columnCells(cells [][]string, i int) []string {
+	row := make([]string, len(cells))
+	for c := range cells {
+		row[c] = cells[c][i]
+	}
+	return row
+}
+
+func alignCell(cell string, column Column, width int) string {
+	if column.Align == AlignRight {
+		return W.PadLeft(cell, width)
+	}
+	return W.PadRight(cell, width)
+}
@@ -0,0 +1,126 @@
+package width
+
+import (
+	"strings"
+	"unicode"
+)
+
+/*This function returns the number of terminal cells a string occupies. ANSI
+color sequences take no room, every grapheme cluster is measured as a whole:
+wide and fullwidth characters take two cells, combining marks, variation
+selectors and emoji modifiers take none, and ZWJ emoji sequences and flags
+count as a single two cell picture*/
+func DisplayWidth(s string) int {
+	width := 0
+	runes := []rune(s)
+
+	for i := 0; i < len(runes); {
+		if runes[i] == 0x1b {
+			i = skipEscape(runes, i)
+			continue
+		}
+
+		clusterWidth := runeWidth(runes[i])
+		regional := isRegionalIndicator(runes[i])
+		i++
+
+	extend:
+		for i < len(runes) {
+			r := runes[i]
+			switch {
+			case r == 0x200d && i+1 < len(runes):
+				// zero width joiner: the next character joins the picture
+				i += 2
+			case r == 0xfe0f:
+				// emoji presentation selector
+				clusterWidth = 2
+				i++
+			case regional && isRegionalIndicator(r):
+				// two regional indicators are a single flag
+				clusterWidth = 2
+				regional = false
+				i++
+			case isExtender(r):
+				i++
+			default:
+				break extend
+			}
+		}
+		width += clusterWidth
+	}
+
+	return width
+}
+
+//Pads s with spaces on the right up to width terminal cells
+func PadRight(s string, width int) string {
+	if pad := width - DisplayWidth(s); pad > 0 {
+		return s + strings.Repeat(" ", pad)
+	}
+	return s
+}
+
+//Pads s with spaces on the left up to width terminal cells
+func PadLeft(s string, width int) string {
+	if pad := width - DisplayWidth(s); pad > 0 {
+		return strings.Repeat(" ", pad) + s
+	}
+	return s
+}
+
+// Cells taken by a character that starts a grapheme cluster.
+func runeWidth(r rune) int {
+	switch {
+	case r < 0x20 || (r >= 0x7f && r < 0xa0):
+		return 0
+	case isExtender(r):
+		return 0
+	case isWide(r):
+		return 2
+	}
+	return 1
+}
+
+/*Characters that never start a cluster of their own: combining and enclosing
+marks, format characters, variation selectors, emoji skin tone modifiers and
+the Hangul medial vowels and final consonants*/
+func isExtender(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc, unicode.Cf) ||
+		(r >= 0xfe00 && r <= 0xfe0f) ||
+		(r >= 0x1f3fb && r <= 0x1f3ff) ||
+		(r >= 0x1160 && r <= 0x11ff) ||
+		(r >= 0xe0100 && r <= 0xe01ef)
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1f1e6 && r <= 0x1f1ff
+}
+
+// Binary search of the East Asian Wide and Fullwidth ranges.
+func isWide(r rune) bool {
+	lo, hi := 0, len(wideRanges)-1
+	for lo <= hi {
+		mid := (lo + hi) / 2
+		switch {
+		case r < wideRanges[mid][0]:
+			hi = mid - 1
+		case r > wideRanges[mid][1]:
+			lo = mid + 1
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the index just past an ANSI escape sequence starting at i.
+func skipEscape(runes []rune, i int) int {
+	i++
+	if i < len(runes) && runes[i] == '[' {
+		i++
+		for i < len(runes) && (runes[i] < 0x40 || runes[i] > 0x7e) {
+			i++
+		}
+	}
+	return i + 1
+}